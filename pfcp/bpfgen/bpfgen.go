@@ -0,0 +1,242 @@
+// Package bpfgen compiles PFCP Outer Header Creation-derived forwarding
+// rules into a classic BPF (cBPF) program, suitable for attaching to a raw
+// socket, AF_PACKET socket, or TC filter via SO_ATTACH_FILTER. Matching a
+// rule returns that rule's 1-based index; no rule matching returns 0.
+//
+// The generated program assumes frames start at an Ethernet header and
+// understands up to one level of 802.1Q (C-TAG) or 802.1ad+802.1Q (S-TAG +
+// C-TAG) VLAN tagging before the IPv4/IPv6 EtherType, matching the outer
+// frames produced by package encap.
+package bpfgen
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+// Rule is a single forwarding classification rule, derived from a parsed
+// Outer Header Creation IE. A frame matches a rule when its outer
+// IPv4/IPv6 destination address, UDP destination port (2152 for GTP-U
+// variants, the parsed PortNumber for plain UDP variants), and GTP-U TEID
+// (when present) all match those carried by Fields.
+//
+// PDI match criteria (source interface, F-TEID, etc.) are not yet
+// represented here; Rule is expected to grow additional fields as the PFCP
+// IE model in package pfcp does.
+type Rule struct {
+	Fields *pfcp.OuterHeaderCreationFields
+}
+
+// Program is a generated, not-yet-assembled cBPF classifier.
+type Program []bpf.Instruction
+
+// Assemble converts p into the raw instructions the kernel (or an emulated
+// bpf.VM) understands.
+func (p Program) Assemble() ([]bpf.RawInstruction, error) {
+	return bpf.Assemble(p)
+}
+
+// Disassemble converts raw instructions (e.g. a classifier previously
+// produced by Assemble) back into a Program, for tests and debugging.
+func Disassemble(raw []bpf.RawInstruction) Program {
+	prog := make(Program, len(raw))
+	for i, ri := range raw {
+		prog[i] = ri.Disassemble()
+	}
+	return prog
+}
+
+const (
+	ethTypeOffset = 12 // Ethernet dst(6)+src(6), then EtherType/TPID
+	ethTypeIPv4   = 0x0800
+	ethTypeIPv6   = 0x86dd
+	tpid8021Q     = 0x8100
+	tpid8021AD    = 0x88a8
+
+	ipv4HeaderLen = 20
+	ipv6HeaderLen = 40
+	udpHeaderLen  = 8
+	// gtpuTEIDOff is the TEID offset within the GTP-U header (TS 29.281
+	// Figure 4.2.2-1). It is fixed at byte 4 regardless of the optional E/S/PN
+	// flags in byte 0: those flags control whether the Sequence Number,
+	// N-PDU Number, and Next Extension Header Type fields *following* the
+	// TEID are present, not the TEID's own position. So, unlike the VLAN
+	// prologue's load-from-X-register handling of a genuinely variable
+	// offset, no equivalent register-based shift is needed here to match on
+	// TEID.
+	gtpuTEIDOff = 4
+
+	ipv4IHLMask = 0x0f // low nibble of the first IPv4 header octet
+	ipv4IHLWant = 5    // IHL in 32-bit words for an options-free header
+
+	ipv4DstOff = 16 // dst address offset within an options-free IPv4 header
+	ipv6DstOff = 24 // dst address offset within an IPv6 header
+)
+
+// Generate compiles rules into a cBPF classifier program. Rules are tried in
+// order; a frame that does not match any rule's protocol dispatch, address,
+// port, or TEID checks falls through to the next rule, and RetConstant(0) if
+// none match.
+func Generate(rules []Rule) (Program, error) {
+	var prog Program
+	prog = append(prog, vlanUnwrapPrologue()...)
+
+	for i, rule := range rules {
+		block, err := ruleBlock(rule, uint32(i+1))
+		if err != nil {
+			return nil, fmt.Errorf("bpfgen: rule %d: %w", i, err)
+		}
+		prog = append(prog, block...)
+	}
+
+	prog = append(prog, bpf.RetConstant{Val: 0})
+	return prog, nil
+}
+
+// vlanUnwrapPrologue loads the Ethernet EtherType/TPID field and leaves X
+// set to the number of VLAN tag bytes (0, 4, or 8) preceding the real
+// EtherType, so every later LoadIndirect in the program can address fields
+// relative to the untagged frame layout by adding X.
+func vlanUnwrapPrologue() Program {
+	return Program{
+		// 0: A = EtherType/TPID
+		bpf.LoadAbsolute{Off: ethTypeOffset, Size: 2},
+		// 1: 802.1ad (S-TAG) -> branch at 7
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: tpid8021AD, SkipTrue: 5, SkipFalse: 0},
+		// 2: 802.1Q (C-TAG only) -> branch at 5
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: tpid8021Q, SkipTrue: 2, SkipFalse: 0},
+		// 3: untagged
+		bpf.LoadConstant{Dst: bpf.RegX, Val: 0},
+		// 4: -> afterVlan (12)
+		bpf.Jump{Skip: 7},
+		// 5: single C-TAG
+		bpf.LoadConstant{Dst: bpf.RegX, Val: 4},
+		// 6: -> afterVlan (12)
+		bpf.Jump{Skip: 5},
+		// 7: S-TAG seen; check for a nested C-TAG
+		bpf.LoadAbsolute{Off: ethTypeOffset + 4, Size: 2},
+		// 8: nested C-TAG -> branch at 11
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: tpid8021Q, SkipTrue: 2, SkipFalse: 0},
+		// 9: S-TAG only
+		bpf.LoadConstant{Dst: bpf.RegX, Val: 4},
+		// 10: -> afterVlan (12)
+		bpf.Jump{Skip: 1},
+		// 11: S-TAG + C-TAG
+		bpf.LoadConstant{Dst: bpf.RegX, Val: 8},
+		// 12: afterVlan
+	}
+}
+
+// checkGroup is one self-contained match condition: a sequence of
+// instructions whose last element is the bpf.JumpIf deciding whether the
+// rule continues (SkipTrue, left 0 so it falls through to the next
+// instruction) or abandons this rule for the next one (SkipFalse, patched by
+// ruleBlock once the full sequence of groups is known).
+type checkGroup struct {
+	insns []bpf.Instruction
+}
+
+// simpleCheck matches a loaded field against val exactly.
+func simpleCheck(load bpf.Instruction, val uint32) checkGroup {
+	return checkGroup{insns: []bpf.Instruction{load, bpf.JumpIf{Cond: bpf.JumpEqual, Val: val}}}
+}
+
+// maskedCheck matches a loaded field against val after masking off bits not
+// relevant to the comparison (e.g. an IPv4 header's version nibble when only
+// the IHL nibble is being checked).
+func maskedCheck(load bpf.Instruction, mask, val uint32) checkGroup {
+	return checkGroup{insns: []bpf.Instruction{
+		load,
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: mask},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: val},
+	}}
+}
+
+// ruleBlock compiles a single rule into a self-contained instruction
+// sequence: each check that fails jumps past the rest of the block
+// (including its own RetConstant) to the next rule, implementing
+// fall-through-on-mismatch.
+func ruleBlock(rule Rule, matchIdx uint32) (Program, error) {
+	f := rule.Fields
+	useV6 := f.HasIPv6() && f.IPv4Address == nil
+
+	ipBase := uint32(ethTypeOffset + 2) // first byte of the IP header, relative to X
+	ipHeaderLen := uint32(ipv4HeaderLen)
+	if useV6 {
+		ipHeaderLen = ipv6HeaderLen
+	}
+
+	var groups []checkGroup
+	if useV6 {
+		ip6 := f.IPv6Address.To16()
+		if ip6 == nil {
+			return nil, fmt.Errorf("IPv6Address is invalid")
+		}
+		groups = append(groups, simpleCheck(bpf.LoadIndirect{Off: ethTypeOffset, Size: 2}, ethTypeIPv6))
+		for w := 0; w < 4; w++ {
+			groups = append(groups, simpleCheck(
+				bpf.LoadIndirect{Off: ipBase + ipv6DstOff + uint32(4*w), Size: 4},
+				binary.BigEndian.Uint32(ip6[4*w:4*w+4]),
+			))
+		}
+	} else if f.HasIPv4() {
+		ip4 := f.IPv4Address.To4()
+		if ip4 == nil {
+			return nil, fmt.Errorf("IPv4Address is invalid")
+		}
+		groups = append(groups, simpleCheck(bpf.LoadIndirect{Off: ethTypeOffset, Size: 2}, ethTypeIPv4))
+		// The port/TEID offsets below assume an options-free IPv4 header
+		// (IHL=5); without this check, an IPv4 frame carrying options would
+		// be silently misclassified instead of rejected.
+		groups = append(groups, maskedCheck(bpf.LoadIndirect{Off: ipBase, Size: 1}, ipv4IHLMask, ipv4IHLWant))
+		groups = append(groups, simpleCheck(
+			bpf.LoadIndirect{Off: ipBase + ipv4DstOff, Size: 4},
+			binary.BigEndian.Uint32(ip4),
+		))
+	} else {
+		return nil, fmt.Errorf("Outer Header Creation fields carry no destination address")
+	}
+
+	if f.HasTEID() || f.HasPort() {
+		dstPort := f.PortNumber
+		if f.HasTEID() {
+			dstPort = 2152 // GTPUPort, see package encap
+		}
+		groups = append(groups, simpleCheck(
+			bpf.LoadIndirect{Off: ipBase + ipHeaderLen + 2, Size: 2},
+			uint32(dstPort),
+		))
+	}
+
+	if f.HasTEID() {
+		groups = append(groups, simpleCheck(
+			bpf.LoadIndirect{Off: ipBase + ipHeaderLen + udpHeaderLen + gtpuTEIDOff, Size: 4},
+			f.TEID,
+		))
+	}
+
+	var block Program
+	for i, g := range groups {
+		remaining := 1 // this rule's final RetConstant
+		for _, later := range groups[i+1:] {
+			remaining += len(later.insns)
+		}
+		if remaining > 0xff {
+			return nil, fmt.Errorf("too many match conditions to encode (have %d)", remaining)
+		}
+
+		last := len(g.insns) - 1
+		jmp := g.insns[last].(bpf.JumpIf)
+		jmp.SkipFalse = uint8(remaining)
+		g.insns[last] = jmp
+
+		block = append(block, g.insns...)
+	}
+	block = append(block, bpf.RetConstant{Val: matchIdx})
+
+	return block, nil
+}
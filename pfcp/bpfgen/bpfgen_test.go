@@ -0,0 +1,166 @@
+package bpfgen
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+// buildFrame assembles a minimal Ethernet(+optional VLAN)/IPv4/UDP/GTP-U
+// frame for feeding into the generated classifier's bpf.VM.
+func buildFrame(t *testing.T, vlan []uint16, dstIP net.IP, dstPort uint16, teid uint32, hasGTPU bool) []byte {
+	t.Helper()
+	var frame []byte
+	frame = append(frame, make([]byte, 12)...) // dst/src MAC, unused by the classifier
+
+	for _, tpid := range vlan {
+		tag := make([]byte, 4)
+		tag[0], tag[1] = byte(tpid>>8), byte(tpid)
+		frame = append(frame, tag...)
+	}
+
+	frame = append(frame, 0x08, 0x00) // EtherType IPv4
+
+	ip := make([]byte, ipv4HeaderLen)
+	ip[0] = 0x45
+	copy(ip[16:20], dstIP.To4())
+	frame = append(frame, ip...)
+
+	udpLen := udpHeaderLen
+	if hasGTPU {
+		udpLen += 8
+	}
+	udp := make([]byte, udpLen)
+	udp[2], udp[3] = byte(dstPort>>8), byte(dstPort)
+	if hasGTPU {
+		udp[8] = 0x30
+		udp[9] = 0xff
+		udp[12], udp[13], udp[14], udp[15] = byte(teid>>24), byte(teid>>16), byte(teid>>8), byte(teid)
+	}
+	frame = append(frame, udp...)
+
+	return frame
+}
+
+func runVM(t *testing.T, prog Program, frame []byte) int {
+	t.Helper()
+	vm, err := bpf.NewVM(prog)
+	require.NoError(t, err)
+	n, err := vm.Run(frame)
+	require.NoError(t, err)
+	return n
+}
+
+func TestGenerate_MatchesGTPUv4ByTEID(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(0xAABBCCDD, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, nil, net.IP{192, 0, 2, 1}, 2152, 0xAABBCCDD, true))
+	assert.Equal(t, 1, match)
+
+	miss := runVM(t, prog, buildFrame(t, nil, net.IP{192, 0, 2, 1}, 2152, 0x11111111, true))
+	assert.Equal(t, 0, miss)
+}
+
+func TestGenerate_VLANUnwrapCTag(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(7, net.IP{10, 0, 0, 5})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, []uint16{tpid8021Q}, net.IP{10, 0, 0, 5}, 2152, 7, true))
+	assert.Equal(t, 1, match)
+}
+
+func TestGenerate_VLANUnwrapSTagAndCTag(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(9, net.IP{10, 0, 0, 6})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, []uint16{tpid8021AD, tpid8021Q}, net.IP{10, 0, 0, 6}, 2152, 9, true))
+	assert.Equal(t, 1, match)
+}
+
+func TestGenerate_VLANUnwrapSTagOnly(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(11, net.IP{10, 0, 0, 7})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, []uint16{tpid8021AD}, net.IP{10, 0, 0, 7}, 2152, 11, true))
+	assert.Equal(t, 1, match)
+}
+
+func TestGenerate_FallsThroughToNextRuleOnMismatch(t *testing.T) {
+	rule1, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+	rule2, err := pfcp.NewGTPUv4(2, net.IP{10, 0, 0, 2})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: rule1}, {Fields: rule2}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, nil, net.IP{10, 0, 0, 2}, 2152, 2, true))
+	assert.Equal(t, 2, match)
+}
+
+func TestGenerate_UDPOnlyVariantUsesParsedPort(t *testing.T) {
+	fields, err := pfcp.NewUDPv4(net.IP{10, 1, 1, 1}, 9999)
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	match := runVM(t, prog, buildFrame(t, nil, net.IP{10, 1, 1, 1}, 9999, 0, false))
+	assert.Equal(t, 1, match)
+
+	miss := runVM(t, prog, buildFrame(t, nil, net.IP{10, 1, 1, 1}, 2152, 0, false))
+	assert.Equal(t, 0, miss)
+}
+
+func TestGenerate_RejectsIPv4FrameWithOptions(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	frame := buildFrame(t, nil, net.IP{10, 0, 0, 1}, 2152, 1, true)
+	frame[14] = 0x46 // version 4, IHL 6: one 32-bit word of IPv4 options
+
+	miss := runVM(t, prog, frame)
+	assert.Equal(t, 0, miss)
+}
+
+func TestGenerate_RejectsRuleWithNoAddress(t *testing.T) {
+	_, err := Generate([]Rule{{Fields: &pfcp.OuterHeaderCreationFields{}}})
+	assert.Error(t, err)
+}
+
+func TestProgram_AssembleDisassembleRoundTrip(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	prog, err := Generate([]Rule{{Fields: fields}})
+	require.NoError(t, err)
+
+	raw, err := prog.Assemble()
+	require.NoError(t, err)
+
+	back := Disassemble(raw)
+	rawAgain, err := back.Assemble()
+	require.NoError(t, err)
+	assert.Equal(t, raw, rawAgain)
+}
@@ -0,0 +1,336 @@
+// Package encap turns a parsed Outer Header Creation IE (see package pfcp)
+// into an actual outer frame: IPv4/IPv6 + UDP + GTP-U, optionally wrapped in
+// 802.1Q/802.1ad VLAN tags when the IE carries C-TAG/S-TAG. It is the
+// datapath counterpart to pfcp.ParseOuterHeaderCreation: given the control
+// plane's decoded instruction and an inner L3 payload, it produces the bytes
+// a UPF would actually put on the wire.
+//
+// The package does not emit an Ethernet header (destination/source MAC);
+// callers that need a full Ethernet frame are expected to prepend that
+// themselves using the addressing information they already have for the
+// next hop. When C-TAG/S-TAG are present, the package does emit the
+// terminal IPv4/IPv6 EtherType after the last VLAN tag, since that EtherType
+// sits between encap's own tags and encap's own IP header and a caller has
+// no seam to interpose it at.
+package encap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+const (
+	// GTPUPort is the well-known UDP destination/source port for GTP-U
+	// (TS 29.281 §4.4.2.0). It is used whenever the Outer Header Creation
+	// fields carry a TEID, regardless of the parsed PortNumber.
+	GTPUPort uint16 = 2152
+
+	ipv4HeaderLen = 20
+	ipv6HeaderLen = 40
+	udpHeaderLen  = 8
+	gtpuHeaderLen = 8
+	vlanTagLen    = 4
+
+	tpid8021Q  uint16 = 0x8100
+	tpid8021AD uint16 = 0x88A8
+
+	ethTypeIPv4 uint16 = 0x0800
+	ethTypeIPv6 uint16 = 0x86DD
+
+	protoUDP        = 17
+	protoIPIPv4     = 4  // IPv4-in-IPv4 / IPv4-in-IPv6
+	protoIPIPv6     = 41 // IPv6-in-IPv4 / IPv6-in-IPv6
+	gtpuMsgTypeGPDU = 0xFF
+)
+
+// Len returns the number of bytes EncapInto will write for the given fields
+// and inner payload length, so callers can size dst without a trial call.
+func Len(fields *pfcp.OuterHeaderCreationFields, innerLen int) int {
+	n := innerLen
+	if fields.HasSTag() {
+		n += vlanTagLen
+	}
+	if fields.HasCTag() {
+		n += vlanTagLen
+	}
+	if fields.HasSTag() || fields.HasCTag() {
+		n += 2 // terminal IPv4/IPv6 EtherType after the last VLAN tag
+	}
+	if fields.HasIPv6() && fields.IPv4Address == nil {
+		n += ipv6HeaderLen
+	} else {
+		n += ipv4HeaderLen
+	}
+	if fields.HasTEID() {
+		n += udpHeaderLen + gtpuHeaderLen
+	} else if fields.HasPort() {
+		n += udpHeaderLen
+	}
+	return n
+}
+
+// EncapInto writes the outer frame for fields+inner into dst and returns the
+// number of bytes written. dst must be at least Len(fields, len(inner))
+// bytes; EncapInto never allocates. srcIP/srcPort are the local tunnel
+// endpoint; the destination address/port come from fields.
+func EncapInto(dst []byte, fields *pfcp.OuterHeaderCreationFields, srcIP net.IP, srcPort uint16, inner []byte) (int, error) {
+	useV6 := fields.HasIPv6() && fields.IPv4Address == nil
+	var dstIP net.IP
+	if useV6 {
+		dstIP = fields.IPv6Address.To16()
+		if dstIP == nil {
+			return 0, fmt.Errorf("encap: Outer Header Creation flags require IPv6 but IPv6Address is invalid")
+		}
+	} else if fields.HasIPv4() {
+		dstIP = fields.IPv4Address.To4()
+		if dstIP == nil {
+			return 0, fmt.Errorf("encap: Outer Header Creation flags require IPv4 but IPv4Address is invalid")
+		}
+	} else {
+		return 0, fmt.Errorf("encap: Outer Header Creation fields carry no destination address")
+	}
+
+	srcIPn := srcIP.To4()
+	if useV6 {
+		srcIPn = srcIP.To16()
+		if srcIPn == nil || srcIP.To4() != nil {
+			return 0, fmt.Errorf("encap: srcIP %v is not a valid IPv6 address for an IPv6 destination", srcIP)
+		}
+	} else if srcIPn == nil {
+		return 0, fmt.Errorf("encap: srcIP %v is not a valid IPv4 address for an IPv4 destination", srcIP)
+	}
+
+	needed := Len(fields, len(inner))
+	if len(dst) < needed {
+		return 0, fmt.Errorf("encap: dst too small: need %d bytes, have %d", needed, len(dst))
+	}
+
+	off := 0
+	if fields.HasSTag() {
+		off += writeVLANTag(dst[off:], tpid8021AD, fields.STag)
+	}
+	if fields.HasCTag() {
+		off += writeVLANTag(dst[off:], tpid8021Q, fields.CTag)
+	}
+	if fields.HasSTag() || fields.HasCTag() {
+		etherType := ethTypeIPv4
+		if useV6 {
+			etherType = ethTypeIPv6
+		}
+		binary.BigEndian.PutUint16(dst[off:off+2], etherType)
+		off += 2
+	}
+
+	hasUDP := fields.HasTEID() || fields.HasPort()
+	l4Len := 0
+	if hasUDP {
+		l4Len = udpHeaderLen
+		if fields.HasTEID() {
+			l4Len += gtpuHeaderLen + len(inner)
+		} else {
+			l4Len += len(inner)
+		}
+	}
+
+	var l4Proto uint8
+	if hasUDP {
+		l4Proto = protoUDP
+	} else if useV6 {
+		l4Proto = ipInIPProto(inner, protoIPIPv6)
+	} else {
+		l4Proto = ipInIPProto(inner, protoIPIPv4)
+	}
+
+	var ipHeaderLen int
+	if useV6 {
+		ipHeaderLen = writeIPv6Header(dst[off:], srcIPn, dstIP, l4Proto, l4Len+len(nonUDPInner(hasUDP, inner)))
+	} else {
+		ipHeaderLen = writeIPv4Header(dst[off:], srcIPn, dstIP, l4Proto, l4Len+len(nonUDPInner(hasUDP, inner)))
+	}
+	ipStart := off
+	off += ipHeaderLen
+
+	if !hasUDP {
+		copy(dst[off:], inner)
+		off += len(inner)
+		if !useV6 {
+			binary.BigEndian.PutUint16(dst[ipStart+10:ipStart+12], 0)
+			binary.BigEndian.PutUint16(dst[ipStart+10:ipStart+12], checksum(dst[ipStart:ipStart+ipv4HeaderLen]))
+		}
+		return off, nil
+	}
+
+	udpStart := off
+	dstPort := fields.PortNumber
+	if fields.HasTEID() {
+		dstPort = GTPUPort
+	}
+	writeUDPHeader(dst[udpStart:], srcPort, dstPort, l4Len)
+	off += udpHeaderLen
+
+	if fields.HasTEID() {
+		writeGTPUHeader(dst[off:], fields.TEID, len(inner))
+		off += gtpuHeaderLen
+	}
+
+	copy(dst[off:], inner)
+	off += len(inner)
+
+	udpSegment := dst[udpStart:off]
+	var udpCsum uint16
+	if useV6 {
+		udpCsum = l4Checksum(srcIPn, dstIP, udpSegment, protoUDP, true)
+	} else {
+		udpCsum = l4Checksum(srcIPn, dstIP, udpSegment, protoUDP, false)
+	}
+	binary.BigEndian.PutUint16(dst[udpStart+6:udpStart+8], udpCsum)
+
+	if !useV6 {
+		binary.BigEndian.PutUint16(dst[ipStart+10:ipStart+12], 0)
+		binary.BigEndian.PutUint16(dst[ipStart+10:ipStart+12], checksum(dst[ipStart:ipStart+ipv4HeaderLen]))
+	}
+
+	return off, nil
+}
+
+// Encap is the allocating convenience wrapper around EncapInto.
+func Encap(fields *pfcp.OuterHeaderCreationFields, srcIP net.IP, srcPort uint16, inner []byte) ([]byte, error) {
+	buf := make([]byte, Len(fields, len(inner)))
+	n, err := EncapInto(buf, fields, srcIP, srcPort, inner)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// nonUDPInner returns inner when there is no UDP/GTP-U layer (i.e. the inner
+// payload sits directly inside the outer IP header, as with the IPv4/IPv6-only
+// Outer Header Creation variants), and nil otherwise so its length doesn't
+// get double-counted by the caller.
+func nonUDPInner(hasUDP bool, inner []byte) []byte {
+	if hasUDP {
+		return nil
+	}
+	return inner
+}
+
+// ipInIPProto guesses the IP-in-IP protocol number from the inner payload's
+// version nibble, falling back to dflt (the protocol matching the outer IP
+// version) if the payload is empty or not a recognizable IP packet.
+func ipInIPProto(inner []byte, dflt uint8) uint8 {
+	if len(inner) == 0 {
+		return dflt
+	}
+	switch inner[0] >> 4 {
+	case 4:
+		return protoIPIPv4
+	case 6:
+		return protoIPIPv6
+	default:
+		return dflt
+	}
+}
+
+func writeVLANTag(dst []byte, tpid uint16, tag uint32) int {
+	binary.BigEndian.PutUint16(dst[0:2], tpid)
+	// TS 29.244 C-TAG/S-TAG fields carry PCP/DEI/VID plus presence flags in
+	// their top octet; we pass the low 16 bits through as the 802.1Q/802.1ad
+	// TCI (PCP:3, DEI:1, VID:12) unchanged, which is the portion a VLAN
+	// switch actually inspects.
+	binary.BigEndian.PutUint16(dst[2:4], uint16(tag))
+	return vlanTagLen
+}
+
+func writeIPv4Header(dst []byte, src, dst4 net.IP, proto uint8, payloadLen int) int {
+	dst[0] = 0x45 // version 4, IHL 5 (no options)
+	dst[1] = 0
+	binary.BigEndian.PutUint16(dst[2:4], uint16(ipv4HeaderLen+payloadLen))
+	binary.BigEndian.PutUint16(dst[4:6], 0) // identification
+	binary.BigEndian.PutUint16(dst[6:8], 0) // flags/fragment offset
+	dst[8] = 64                             // TTL
+	dst[9] = proto
+	binary.BigEndian.PutUint16(dst[10:12], 0) // checksum, filled in by caller
+	copy(dst[12:16], src.To4())
+	copy(dst[16:20], dst4.To4())
+	return ipv4HeaderLen
+}
+
+func writeIPv6Header(dst []byte, src, dst6 net.IP, nextHeader uint8, payloadLen int) int {
+	binary.BigEndian.PutUint32(dst[0:4], 6<<28) // version 6, traffic class/flow label 0
+	binary.BigEndian.PutUint16(dst[4:6], uint16(payloadLen))
+	dst[6] = nextHeader
+	dst[7] = 64 // hop limit
+	copy(dst[8:24], src.To16())
+	copy(dst[24:40], dst6.To16())
+	return ipv6HeaderLen
+}
+
+func writeUDPHeader(dst []byte, srcPort, dstPort uint16, length int) {
+	binary.BigEndian.PutUint16(dst[0:2], srcPort)
+	binary.BigEndian.PutUint16(dst[2:4], dstPort)
+	binary.BigEndian.PutUint16(dst[4:6], uint16(length))
+	binary.BigEndian.PutUint16(dst[6:8], 0) // checksum, filled in by caller
+}
+
+func writeGTPUHeader(dst []byte, teid uint32, payloadLen int) {
+	dst[0] = 0x30 // version 1, protocol type GTP, no E/S/PN
+	dst[1] = gtpuMsgTypeGPDU
+	binary.BigEndian.PutUint16(dst[2:4], uint16(payloadLen))
+	binary.BigEndian.PutUint32(dst[4:8], teid)
+}
+
+// checksum computes the Internet checksum (RFC 1071) over b.
+func checksum(b []byte) uint16 {
+	return foldChecksum(onesComplementSum(b, 0))
+}
+
+func onesComplementSum(b []byte, initial uint32) uint32 {
+	sum := initial
+	n := len(b)
+	for i := 0; i+1 < n; i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if n%2 == 1 {
+		sum += uint32(b[n-1]) << 8
+	}
+	return sum
+}
+
+func foldChecksum(sum uint32) uint16 {
+	for sum>>16 != 0 {
+		sum = (sum & 0xFFFF) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
+
+// l4Checksum computes the UDP checksum over udpSegment (header, with its
+// checksum field still zero, followed by payload) using the IPv4 or IPv6
+// pseudo-header, per RFC 768 / RFC 8200 §8.1.
+func l4Checksum(src, dst net.IP, udpSegment []byte, proto uint8, v6 bool) uint16 {
+	var sum uint32
+	if v6 {
+		pseudo := make([]byte, 40)
+		copy(pseudo[0:16], src.To16())
+		copy(pseudo[16:32], dst.To16())
+		binary.BigEndian.PutUint32(pseudo[32:36], uint32(len(udpSegment)))
+		pseudo[39] = proto
+		sum = onesComplementSum(pseudo, 0)
+	} else {
+		pseudo := make([]byte, 12)
+		copy(pseudo[0:4], src.To4())
+		copy(pseudo[4:8], dst.To4())
+		pseudo[9] = proto
+		binary.BigEndian.PutUint16(pseudo[10:12], uint16(len(udpSegment)))
+		sum = onesComplementSum(pseudo, 0)
+	}
+	sum = onesComplementSum(udpSegment, sum)
+	cs := foldChecksum(sum)
+	if cs == 0 {
+		// RFC 768: a computed checksum of 0 is transmitted as all-ones.
+		cs = 0xFFFF
+	}
+	return cs
+}
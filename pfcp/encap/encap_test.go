@@ -0,0 +1,151 @@
+package encap
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+func TestEncap_GTPUv4(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(0x11223344, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	inner := []byte{0xde, 0xad, 0xbe, 0xef}
+	out, err := Encap(fields, net.IP{192, 0, 2, 2}, 2152, inner)
+	require.NoError(t, err)
+	assert.Equal(t, Len(fields, len(inner)), len(out))
+
+	// IPv4 header.
+	assert.Equal(t, byte(0x45), out[0])
+	assert.Equal(t, uint16(ipv4HeaderLen+udpHeaderLen+gtpuHeaderLen+len(inner)), binary.BigEndian.Uint16(out[2:4]))
+	assert.Equal(t, uint8(protoUDP), out[9])
+	assert.Equal(t, net.IP{192, 0, 2, 2}.To4(), net.IP(out[12:16]))
+	assert.Equal(t, net.IP{192, 0, 2, 1}.To4(), net.IP(out[16:20]))
+	assert.Equal(t, uint16(0), checksum(out[0:ipv4HeaderLen]))
+
+	// UDP header.
+	udp := out[ipv4HeaderLen:]
+	assert.Equal(t, uint16(2152), binary.BigEndian.Uint16(udp[0:2]))
+	assert.Equal(t, uint16(GTPUPort), binary.BigEndian.Uint16(udp[2:4]))
+	assert.Equal(t, uint16(udpHeaderLen+gtpuHeaderLen+len(inner)), binary.BigEndian.Uint16(udp[4:6]))
+
+	// GTP-U header.
+	gtpu := udp[udpHeaderLen:]
+	assert.Equal(t, byte(0x30), gtpu[0])
+	assert.Equal(t, byte(0xFF), gtpu[1])
+	assert.Equal(t, uint16(len(inner)), binary.BigEndian.Uint16(gtpu[2:4]))
+	assert.Equal(t, uint32(0x11223344), binary.BigEndian.Uint32(gtpu[4:8]))
+
+	assert.Equal(t, inner, gtpu[gtpuHeaderLen:])
+}
+
+func TestEncap_GTPUv6(t *testing.T) {
+	fields, err := pfcp.NewGTPUv6(7, net.ParseIP("2001:db8::2"))
+	require.NoError(t, err)
+
+	inner := []byte{0x01, 0x02, 0x03}
+	out, err := Encap(fields, net.ParseIP("2001:db8::1"), 2152, inner)
+	require.NoError(t, err)
+	assert.Equal(t, Len(fields, len(inner)), len(out))
+
+	assert.Equal(t, uint8(6), out[0]>>4)
+	assert.Equal(t, uint16(udpHeaderLen+gtpuHeaderLen+len(inner)), binary.BigEndian.Uint16(out[4:6]))
+	assert.Equal(t, uint8(protoUDP), out[6])
+	assert.Equal(t, net.ParseIP("2001:db8::1"), net.IP(out[8:24]))
+	assert.Equal(t, net.ParseIP("2001:db8::2"), net.IP(out[24:40]))
+
+	udp := out[ipv6HeaderLen:]
+	gtpu := udp[udpHeaderLen:]
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(gtpu[4:8]))
+	assert.Equal(t, inner, gtpu[gtpuHeaderLen:])
+}
+
+func TestEncap_UDPv4_NoGTPU(t *testing.T) {
+	fields, err := pfcp.NewUDPv4(net.IP{10, 0, 0, 1}, 9999)
+	require.NoError(t, err)
+
+	inner := []byte{0xAA, 0xBB}
+	out, err := Encap(fields, net.IP{10, 0, 0, 2}, 5000, inner)
+	require.NoError(t, err)
+
+	udp := out[ipv4HeaderLen:]
+	assert.Equal(t, uint16(5000), binary.BigEndian.Uint16(udp[0:2]))
+	assert.Equal(t, uint16(9999), binary.BigEndian.Uint16(udp[2:4]))
+	assert.Equal(t, inner, udp[udpHeaderLen:])
+}
+
+func TestEncap_IPv4Only_NoUDP(t *testing.T) {
+	fields, err := pfcp.NewIPv4(net.IP{172, 16, 0, 1})
+	require.NoError(t, err)
+
+	inner := []byte{0x45, 0x00, 0x00, 0x14} // looks like an IPv4 packet
+	out, err := Encap(fields, net.IP{172, 16, 0, 2}, 0, inner)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint8(protoIPIPv4), out[9])
+	assert.Equal(t, inner, out[ipv4HeaderLen:])
+	assert.Equal(t, uint16(0), checksum(out[0:ipv4HeaderLen]))
+}
+
+func TestEncap_VLANTags(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+	_, err = fields.WithCTag(0x000064)
+	require.NoError(t, err)
+	_, err = fields.WithSTag(0x0000c8)
+	require.NoError(t, err)
+
+	inner := []byte{0x01}
+	out, err := Encap(fields, net.IP{10, 0, 0, 2}, 2152, inner)
+	require.NoError(t, err)
+	require.Equal(t, Len(fields, len(inner)), len(out))
+
+	assert.Equal(t, uint16(tpid8021AD), binary.BigEndian.Uint16(out[0:2]))
+	assert.Equal(t, uint16(0x00c8), binary.BigEndian.Uint16(out[2:4]))
+	assert.Equal(t, uint16(tpid8021Q), binary.BigEndian.Uint16(out[4:6]))
+	assert.Equal(t, uint16(0x0064), binary.BigEndian.Uint16(out[6:8]))
+	assert.Equal(t, uint16(ethTypeIPv4), binary.BigEndian.Uint16(out[8:10]))
+
+	assert.Equal(t, byte(0x45), out[2*vlanTagLen+2])
+}
+
+func TestEncapInto_BufferTooSmall(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	dst := make([]byte, 4)
+	_, err = EncapInto(dst, fields, net.IP{10, 0, 0, 2}, 2152, []byte{0x01})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "too small")
+}
+
+func TestEncap_RejectsNoDestinationAddress(t *testing.T) {
+	fields := &pfcp.OuterHeaderCreationFields{}
+	_, err := Encap(fields, net.IP{10, 0, 0, 2}, 2152, []byte{0x01})
+	assert.Error(t, err)
+}
+
+func TestEncap_RejectsMismatchedSrcFamily(t *testing.T) {
+	fields, err := pfcp.NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	_, err = Encap(fields, net.ParseIP("::1"), 2152, []byte{0x01})
+	assert.Error(t, err)
+}
+
+func TestChecksum_ValidatesAgainstZero(t *testing.T) {
+	// Recomputing the checksum over a header that already contains a
+	// correct checksum field must yield zero.
+	fields, err := pfcp.NewGTPUv4(1, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	out, err := Encap(fields, net.IP{192, 0, 2, 2}, 2152, []byte{0x01, 0x02})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(0), checksum(out[0:ipv4HeaderLen]))
+}
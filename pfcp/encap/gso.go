@@ -0,0 +1,252 @@
+package encap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+// GSOType identifies the inner transport/IP-family combination a
+// GSODescriptor describes, mirroring virtio-net's gso_type field
+// (VIRTIO_NET_HDR_GSO_*) used by WireGuard's TUN offload path.
+type GSOType uint8
+
+const (
+	GSOTCPv4 GSOType = iota + 1
+	GSOTCPv6
+	GSOUDPv4
+	GSOUDPv6
+)
+
+// GSODescriptor is the caller-supplied, virtio-net-style description of a
+// single large inner L3 payload that SegmentAndEncap should split into
+// gsoSize-sized segments.
+type GSODescriptor struct {
+	Type GSOType
+	// HdrLen is the combined length, in bytes, of the inner IP header and
+	// its TCP/UDP header (no IP options, no IPv6 extension headers). It is
+	// cross-checked against the headers actually present in innerL3.
+	HdrLen uint16
+	// CSumStart is the offset of the start of the checksummed region
+	// (the inner IP header's payload, i.e. the L4 header).
+	CSumStart uint16
+	// CSumOffset is the offset, relative to CSumStart, of the checksum
+	// field within the L4 header.
+	CSumOffset uint16
+}
+
+const (
+	protoTCP = 6
+
+	tcpFlagFIN = 0x01
+	tcpFlagPSH = 0x08
+	tcpFlagCWR = 0x80
+)
+
+// SegmentAndEncap splits innerL3 (a single large TCP- or UDP-over-IP payload
+// described by desc) into gsoSize-sized segments, rewrites each segment's IP
+// total length, IP identification (incrementing, IPv4 only), TCP sequence
+// number and FIN/PSH/CWR flags, recomputes IP/L4 checksums, and wraps each
+// segment in the GTP-U/outer-IP/UDP frame described by fields (as EncapInto
+// would). Only the last segment keeps FIN/PSH; CWR is cleared on every
+// segment but the first. UDP segments simply replicate the L4 header with an
+// adjusted length. IPv6 payloads are segmented at L4 only: no IPv6 Fragment
+// header is added.
+//
+// out must have at least as many slices as segments produced, and each
+// out[i] must have enough capacity for that segment's encapsulated size;
+// SegmentAndEncap writes into out[i][:n]. Aside from one reusable scratch
+// buffer sized for a single segment, allocated once up front, the per-segment
+// work (header rewriting, checksums, encapsulation) performs no further
+// allocation. It returns the number of segments written.
+func SegmentAndEncap(fields *pfcp.OuterHeaderCreationFields, srcIP net.IP, srcPort uint16, desc GSODescriptor, innerL3 []byte, gsoSize uint16, out [][]byte) (int, error) {
+	if gsoSize == 0 {
+		return 0, fmt.Errorf("encap: gsoSize must be nonzero")
+	}
+	if desc.HdrLen == 0 || int(desc.HdrLen) > len(innerL3) {
+		return 0, fmt.Errorf("encap: HdrLen %d out of range for a %d-byte payload", desc.HdrLen, len(innerL3))
+	}
+
+	ipVersion, l4Proto, err := desc.Type.split()
+	if err != nil {
+		return 0, err
+	}
+
+	ipHeaderLen, l4HeaderLen, err := inspectInnerHeaders(innerL3, ipVersion, l4Proto)
+	if err != nil {
+		return 0, err
+	}
+	if int(desc.HdrLen) != ipHeaderLen+l4HeaderLen {
+		return 0, fmt.Errorf("encap: HdrLen %d does not match parsed headers (IP %d + L4 %d)", desc.HdrLen, ipHeaderLen, l4HeaderLen)
+	}
+
+	l4Start := ipHeaderLen
+	var wantCSumOffset uint16
+	if l4Proto == protoTCP {
+		wantCSumOffset = 16
+	} else {
+		wantCSumOffset = 6
+	}
+	if int(desc.CSumStart) != l4Start || desc.CSumOffset != wantCSumOffset {
+		return 0, fmt.Errorf("encap: CSumStart/CSumOffset (%d/%d) do not point at the L4 checksum field (want %d/%d)", desc.CSumStart, desc.CSumOffset, l4Start, wantCSumOffset)
+	}
+	csumFieldOffset := int(desc.CSumStart) + int(desc.CSumOffset)
+
+	header := innerL3[:desc.HdrLen]
+	payload := innerL3[desc.HdrLen:]
+	if len(payload) == 0 {
+		return 0, fmt.Errorf("encap: innerL3 has no payload past HdrLen %d to segment", desc.HdrLen)
+	}
+
+	numSegments := (len(payload) + int(gsoSize) - 1) / int(gsoSize)
+	if numSegments > len(out) {
+		return 0, fmt.Errorf("encap: out too small: need %d segment slices, have %d", numSegments, len(out))
+	}
+
+	var origID uint16
+	if ipVersion == 4 {
+		origID = binary.BigEndian.Uint16(header[4:6])
+	}
+	var origSeq uint32
+	var origFlags byte
+	if l4Proto == protoTCP {
+		origSeq = binary.BigEndian.Uint32(header[l4Start+4 : l4Start+8])
+		origFlags = header[l4Start+13]
+	}
+
+	var innerSrc, innerDst net.IP
+	if ipVersion == 4 {
+		innerSrc = net.IP(header[12:16])
+		innerDst = net.IP(header[16:20])
+	} else {
+		innerSrc = net.IP(header[8:24])
+		innerDst = net.IP(header[24:40])
+	}
+
+	// scratch holds one rewritten segment (header + chunk) at a time and is
+	// reused across iterations so the loop itself performs no per-segment
+	// allocation.
+	scratch := make([]byte, int(desc.HdrLen)+int(gsoSize))
+
+	for i := 0; i < numSegments; i++ {
+		start := i * int(gsoSize)
+		end := start + int(gsoSize)
+		if end > len(payload) {
+			end = len(payload)
+		}
+		chunk := payload[start:end]
+
+		segment := scratch[:int(desc.HdrLen)+len(chunk)]
+		copy(segment, header)
+		copy(segment[desc.HdrLen:], chunk)
+
+		if ipVersion == 4 {
+			totalLen := ipHeaderLen + l4HeaderLen + len(chunk)
+			binary.BigEndian.PutUint16(segment[2:4], uint16(totalLen))
+			binary.BigEndian.PutUint16(segment[4:6], origID+uint16(i))
+			binary.BigEndian.PutUint16(segment[10:12], 0)
+			binary.BigEndian.PutUint16(segment[10:12], checksum(segment[0:ipHeaderLen]))
+		} else {
+			binary.BigEndian.PutUint16(segment[4:6], uint16(l4HeaderLen+len(chunk)))
+		}
+
+		if l4Proto == protoTCP {
+			binary.BigEndian.PutUint32(segment[l4Start+4:l4Start+8], origSeq+uint32(start))
+
+			flags := origFlags
+			last := i == numSegments-1
+			if !last {
+				flags &^= tcpFlagFIN | tcpFlagPSH
+			}
+			if i != 0 {
+				flags &^= tcpFlagCWR
+			}
+			segment[l4Start+13] = flags
+
+			binary.BigEndian.PutUint16(segment[csumFieldOffset:csumFieldOffset+2], 0)
+			cs := l4Checksum(innerSrc, innerDst, segment[l4Start:], protoTCP, ipVersion == 6)
+			binary.BigEndian.PutUint16(segment[csumFieldOffset:csumFieldOffset+2], cs)
+		} else {
+			binary.BigEndian.PutUint16(segment[l4Start+4:l4Start+6], uint16(l4HeaderLen+len(chunk)))
+			binary.BigEndian.PutUint16(segment[csumFieldOffset:csumFieldOffset+2], 0)
+			cs := l4Checksum(innerSrc, innerDst, segment[l4Start:], protoUDP, ipVersion == 6)
+			binary.BigEndian.PutUint16(segment[csumFieldOffset:csumFieldOffset+2], cs)
+		}
+
+		n, err := EncapInto(out[i][:cap(out[i])], fields, srcIP, srcPort, segment)
+		if err != nil {
+			return i, fmt.Errorf("encap: segment %d: %w", i, err)
+		}
+		out[i] = out[i][:n]
+	}
+
+	return numSegments, nil
+}
+
+func (t GSOType) split() (ipVersion int, l4Proto uint8, err error) {
+	switch t {
+	case GSOTCPv4:
+		return 4, protoTCP, nil
+	case GSOTCPv6:
+		return 6, protoTCP, nil
+	case GSOUDPv4:
+		return 4, protoUDP, nil
+	case GSOUDPv6:
+		return 6, protoUDP, nil
+	default:
+		return 0, 0, fmt.Errorf("encap: unknown GSOType %d", t)
+	}
+}
+
+// inspectInnerHeaders validates that innerL3 begins with an IP header of the
+// expected version (no options for IPv4, no extension headers for IPv6)
+// followed by a TCP or UDP header, and returns their lengths.
+func inspectInnerHeaders(innerL3 []byte, ipVersion int, l4Proto uint8) (ipHeaderLen, l4HeaderLen int, err error) {
+	if len(innerL3) < 1 {
+		return 0, 0, fmt.Errorf("encap: innerL3 too short to contain an IP header")
+	}
+
+	switch ipVersion {
+	case 4:
+		if len(innerL3) < ipv4HeaderLen {
+			return 0, 0, fmt.Errorf("encap: innerL3 too short for an IPv4 header")
+		}
+		if innerL3[0]>>4 != 4 || innerL3[0]&0x0f != 5 {
+			return 0, 0, fmt.Errorf("encap: innerL3 is not an options-free IPv4 header")
+		}
+		ipHeaderLen = ipv4HeaderLen
+	case 6:
+		if len(innerL3) < ipv6HeaderLen {
+			return 0, 0, fmt.Errorf("encap: innerL3 too short for an IPv6 header")
+		}
+		if innerL3[0]>>4 != 6 {
+			return 0, 0, fmt.Errorf("encap: innerL3 is not an IPv6 header")
+		}
+		ipHeaderLen = ipv6HeaderLen
+	default:
+		return 0, 0, fmt.Errorf("encap: unsupported IP version %d", ipVersion)
+	}
+
+	switch l4Proto {
+	case protoTCP:
+		if len(innerL3) < ipHeaderLen+20 {
+			return 0, 0, fmt.Errorf("encap: innerL3 too short for a TCP header")
+		}
+		dataOffset := int(innerL3[ipHeaderLen+12]>>4) * 4
+		if dataOffset < 20 || len(innerL3) < ipHeaderLen+dataOffset {
+			return 0, 0, fmt.Errorf("encap: invalid TCP data offset %d", dataOffset)
+		}
+		l4HeaderLen = dataOffset
+	case protoUDP:
+		if len(innerL3) < ipHeaderLen+udpHeaderLen {
+			return 0, 0, fmt.Errorf("encap: innerL3 too short for a UDP header")
+		}
+		l4HeaderLen = udpHeaderLen
+	default:
+		return 0, 0, fmt.Errorf("encap: unsupported L4 protocol %d", l4Proto)
+	}
+
+	return ipHeaderLen, l4HeaderLen, nil
+}
@@ -0,0 +1,140 @@
+package encap
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DBGR18/util/pfcp"
+)
+
+// buildTCPv4 builds an options-free IPv4+TCP packet with the given payload,
+// PSH/FIN/CWR all set so tests can observe which flags survive
+// segmentation.
+func buildTCPv4(t *testing.T, payload []byte) []byte {
+	t.Helper()
+	const ipLen, tcpLen = 20, 20
+	pkt := make([]byte, ipLen+tcpLen+len(payload))
+
+	pkt[0] = 0x45
+	binary.BigEndian.PutUint16(pkt[2:4], uint16(len(pkt)))
+	binary.BigEndian.PutUint16(pkt[4:6], 1000) // identification
+	pkt[8] = 64
+	pkt[9] = protoTCP
+	copy(pkt[12:16], net.IP{10, 0, 0, 1}.To4())
+	copy(pkt[16:20], net.IP{10, 0, 0, 2}.To4())
+
+	tcp := pkt[ipLen:]
+	binary.BigEndian.PutUint16(tcp[0:2], 1234)
+	binary.BigEndian.PutUint16(tcp[2:4], 80)
+	binary.BigEndian.PutUint32(tcp[4:8], 5000) // sequence number
+	tcp[12] = 5 << 4                           // data offset = 5 words
+	tcp[13] = tcpFlagCWR | tcpFlagPSH | tcpFlagFIN
+	copy(tcp[20:], payload)
+
+	binary.BigEndian.PutUint16(pkt[10:12], 0)
+	binary.BigEndian.PutUint16(pkt[10:12], checksum(pkt[0:ipLen]))
+	binary.BigEndian.PutUint16(tcp[16:18], 0)
+	cs := l4Checksum(net.IP{10, 0, 0, 1}, net.IP{10, 0, 0, 2}, tcp, protoTCP, false)
+	binary.BigEndian.PutUint16(tcp[16:18], cs)
+
+	return pkt
+}
+
+func TestSegmentAndEncap_TCPv4(t *testing.T) {
+	payload := make([]byte, 2500)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	pkt := buildTCPv4(t, payload)
+
+	fields, err := pfcp.NewGTPUv4(42, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	desc := GSODescriptor{Type: GSOTCPv4, HdrLen: 40, CSumStart: 20, CSumOffset: 16}
+	out := make([][]byte, 3)
+	for i := range out {
+		out[i] = make([]byte, Len(fields, 1100)) // generous capacity
+	}
+
+	n, err := SegmentAndEncap(fields, net.IP{192, 0, 2, 2}, 2152, desc, pkt, 1000, out)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	var gotSeq []uint32
+	var gotFlags []byte
+	var totalPayload int
+	for i := 0; i < n; i++ {
+		decoded := out[i]
+		udp := decoded[ipv4HeaderLen:]
+		gtpu := udp[udpHeaderLen:]
+		inner := gtpu[gtpuHeaderLen:]
+
+		require.Equal(t, byte(0x45), inner[0])
+		innerTCP := inner[20:]
+		seq := binary.BigEndian.Uint32(innerTCP[4:8])
+		gotSeq = append(gotSeq, seq)
+		gotFlags = append(gotFlags, innerTCP[13])
+
+		totalLen := binary.BigEndian.Uint16(inner[2:4])
+		segPayloadLen := int(totalLen) - 20 - 20
+		totalPayload += segPayloadLen
+
+		// Recomputing the checksum over a correctly-checksummed header
+		// must yield zero.
+		assert.Equal(t, uint16(0), checksum(inner[0:20]))
+	}
+
+	assert.Equal(t, len(payload), totalPayload)
+	assert.Equal(t, []uint32{5000, 6000, 7000}, gotSeq)
+
+	// Only the last segment keeps FIN/PSH; CWR only survives on the first.
+	assert.Equal(t, byte(tcpFlagCWR), gotFlags[0])
+	assert.Equal(t, byte(0), gotFlags[1])
+	assert.Equal(t, byte(tcpFlagPSH|tcpFlagFIN), gotFlags[2])
+}
+
+func TestSegmentAndEncap_OutTooSmall(t *testing.T) {
+	pkt := buildTCPv4(t, make([]byte, 2000))
+	fields, err := pfcp.NewGTPUv4(1, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	desc := GSODescriptor{Type: GSOTCPv4, HdrLen: 40, CSumStart: 20, CSumOffset: 16}
+	out := make([][]byte, 1)
+	out[0] = make([]byte, 2000)
+
+	_, err = SegmentAndEncap(fields, net.IP{192, 0, 2, 2}, 2152, desc, pkt, 1000, out)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out too small")
+}
+
+func TestSegmentAndEncap_RejectsMismatchedCSumFields(t *testing.T) {
+	pkt := buildTCPv4(t, make([]byte, 100))
+	fields, err := pfcp.NewGTPUv4(1, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	desc := GSODescriptor{Type: GSOTCPv4, HdrLen: 40, CSumStart: 0, CSumOffset: 0}
+	out := make([][]byte, 1)
+	out[0] = make([]byte, 200)
+
+	_, err = SegmentAndEncap(fields, net.IP{192, 0, 2, 2}, 2152, desc, pkt, 1000, out)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CSumStart")
+}
+
+func TestSegmentAndEncap_RejectsBadHdrLen(t *testing.T) {
+	pkt := buildTCPv4(t, make([]byte, 100))
+	fields, err := pfcp.NewGTPUv4(1, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+
+	desc := GSODescriptor{Type: GSOTCPv4, HdrLen: 41, CSumStart: 20, CSumOffset: 16}
+	out := make([][]byte, 1)
+	out[0] = make([]byte, 200)
+
+	_, err = SegmentAndEncap(fields, net.IP{192, 0, 2, 2}, 2152, desc, pkt, 1000, out)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "HdrLen")
+}
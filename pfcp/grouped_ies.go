@@ -0,0 +1,326 @@
+package pfcp
+
+import "fmt"
+
+// PDI is the PDI (Packet Detection Information) IE (TS 29.244 §7.5.2.2 /
+// 8.2.1): a grouped IE describing how to match packets for a PDR. Other
+// holds any child IEs this package doesn't give a named field to (e.g.
+// Network Instance, SDF Filter), preserved unchanged for re-marshaling.
+type PDI struct {
+	SourceInterface *SourceInterface
+	FTEID           *FTEID
+	Other           []IE
+}
+
+// Type returns IETypePDI.
+func (p *PDI) Type() uint16 { return IETypePDI }
+
+// Marshal serializes p's children, in SourceInterface, F-TEID, Other order.
+func (p *PDI) Marshal() ([]byte, error) {
+	var ies []IE
+	if p.SourceInterface != nil {
+		ies = append(ies, *p.SourceInterface)
+	}
+	if p.FTEID != nil {
+		ies = append(ies, p.FTEID)
+	}
+	ies = append(ies, p.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parsePDI(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: PDI: %w", err)
+	}
+	p := &PDI{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case SourceInterface:
+			if p.SourceInterface == nil {
+				p.SourceInterface = &v
+				continue
+			}
+		case *FTEID:
+			if p.FTEID == nil {
+				p.FTEID = v
+				continue
+			}
+		}
+		p.Other = append(p.Other, c)
+	}
+	return p, nil
+}
+
+func init() { RegisterIEType(IETypePDI, parsePDI) }
+
+// ForwardingParameters is the Forwarding Parameters IE (TS 29.244 §7.5.2.3 /
+// 8.2.27): a grouped IE carried inside a FAR describing where and how to
+// send packets it forwards, including the Outer Header Creation IE this
+// package started with.
+type ForwardingParameters struct {
+	DestinationInterface *DestinationInterface
+	OuterHeaderCreation  *OuterHeaderCreationFields
+	Other                []IE
+}
+
+// Type returns IETypeForwardingParameters.
+func (fp *ForwardingParameters) Type() uint16 { return IETypeForwardingParameters }
+
+// Marshal serializes fp's children, in DestinationInterface, Outer Header
+// Creation, Other order.
+func (fp *ForwardingParameters) Marshal() ([]byte, error) {
+	var ies []IE
+	if fp.DestinationInterface != nil {
+		ies = append(ies, *fp.DestinationInterface)
+	}
+	if fp.OuterHeaderCreation != nil {
+		ies = append(ies, fp.OuterHeaderCreation)
+	}
+	ies = append(ies, fp.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parseForwardingParameters(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: Forwarding Parameters: %w", err)
+	}
+	fp := &ForwardingParameters{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case DestinationInterface:
+			if fp.DestinationInterface == nil {
+				fp.DestinationInterface = &v
+				continue
+			}
+		case *OuterHeaderCreationFields:
+			if fp.OuterHeaderCreation == nil {
+				fp.OuterHeaderCreation = v
+				continue
+			}
+		}
+		fp.Other = append(fp.Other, c)
+	}
+	return fp, nil
+}
+
+func init() { RegisterIEType(IETypeForwardingParameters, parseForwardingParameters) }
+
+// FAR is the Create FAR IE (TS 29.244 §7.5.2.3): a grouped IE
+// establishing a Forwarding Action Rule, nesting Forwarding Parameters
+// (which in turn nests Outer Header Creation).
+type FAR struct {
+	FARID                *FARID
+	ApplyAction          *ApplyAction
+	ForwardingParameters *ForwardingParameters
+	Other                []IE
+}
+
+// Type returns IETypeCreateFAR.
+func (f *FAR) Type() uint16 { return IETypeCreateFAR }
+
+// Marshal serializes f's children, in FAR ID, Apply Action, Forwarding
+// Parameters, Other order.
+func (f *FAR) Marshal() ([]byte, error) {
+	var ies []IE
+	if f.FARID != nil {
+		ies = append(ies, *f.FARID)
+	}
+	if f.ApplyAction != nil {
+		ies = append(ies, *f.ApplyAction)
+	}
+	if f.ForwardingParameters != nil {
+		ies = append(ies, f.ForwardingParameters)
+	}
+	ies = append(ies, f.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parseFAR(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: Create FAR: %w", err)
+	}
+	f := &FAR{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case FARID:
+			if f.FARID == nil {
+				f.FARID = &v
+				continue
+			}
+		case ApplyAction:
+			if f.ApplyAction == nil {
+				f.ApplyAction = &v
+				continue
+			}
+		case *ForwardingParameters:
+			if f.ForwardingParameters == nil {
+				f.ForwardingParameters = v
+				continue
+			}
+		}
+		f.Other = append(f.Other, c)
+	}
+	return f, nil
+}
+
+func init() { RegisterIEType(IETypeCreateFAR, parseFAR) }
+
+// PDR is the Create PDR IE (TS 29.244 §7.5.2.2): a grouped IE
+// establishing a Packet Detection Rule, nesting PDI (which in turn nests
+// Source Interface and F-TEID).
+type PDR struct {
+	PDRID      *PDRID
+	Precedence *Precedence
+	PDI        *PDI
+	FARID      *FARID
+	Other      []IE
+}
+
+// Type returns IETypeCreatePDR.
+func (p *PDR) Type() uint16 { return IETypeCreatePDR }
+
+// Marshal serializes p's children, in PDR ID, Precedence, PDI, FAR ID, Other
+// order.
+func (p *PDR) Marshal() ([]byte, error) {
+	var ies []IE
+	if p.PDRID != nil {
+		ies = append(ies, *p.PDRID)
+	}
+	if p.Precedence != nil {
+		ies = append(ies, *p.Precedence)
+	}
+	if p.PDI != nil {
+		ies = append(ies, p.PDI)
+	}
+	if p.FARID != nil {
+		ies = append(ies, *p.FARID)
+	}
+	ies = append(ies, p.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parsePDR(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: Create PDR: %w", err)
+	}
+	p := &PDR{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case PDRID:
+			if p.PDRID == nil {
+				p.PDRID = &v
+				continue
+			}
+		case Precedence:
+			if p.Precedence == nil {
+				p.Precedence = &v
+				continue
+			}
+		case *PDI:
+			if p.PDI == nil {
+				p.PDI = v
+				continue
+			}
+		case FARID:
+			if p.FARID == nil {
+				p.FARID = &v
+				continue
+			}
+		}
+		p.Other = append(p.Other, c)
+	}
+	return p, nil
+}
+
+func init() { RegisterIEType(IETypeCreatePDR, parsePDR) }
+
+// QER is the Create QER IE (TS 29.244 §7.5.2.4): a grouped IE
+// establishing a QoS Enforcement Rule. Only QER ID is given a named field;
+// the rate/gate-status/marking IEs a real session would also carry (MBR,
+// GBR, Gate Status, ...) round-trip through Other until this package grows
+// first-class support for them.
+type QER struct {
+	QERID *QERID
+	Other []IE
+}
+
+// Type returns IETypeCreateQER.
+func (q *QER) Type() uint16 { return IETypeCreateQER }
+
+// Marshal serializes q's children, in QER ID, Other order.
+func (q *QER) Marshal() ([]byte, error) {
+	var ies []IE
+	if q.QERID != nil {
+		ies = append(ies, *q.QERID)
+	}
+	ies = append(ies, q.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parseQER(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: Create QER: %w", err)
+	}
+	q := &QER{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case QERID:
+			if q.QERID == nil {
+				q.QERID = &v
+				continue
+			}
+		}
+		q.Other = append(q.Other, c)
+	}
+	return q, nil
+}
+
+func init() { RegisterIEType(IETypeCreateQER, parseQER) }
+
+// URR is the Create URR IE (TS 29.244 §7.5.2.5): a grouped IE
+// establishing a Usage Reporting Rule. Like QER, only URR ID is given a
+// named field; the measurement-method/threshold IEs round-trip through
+// Other until this package grows first-class support for them.
+type URR struct {
+	URRID *URRID
+	Other []IE
+}
+
+// Type returns IETypeCreateURR.
+func (u *URR) Type() uint16 { return IETypeCreateURR }
+
+// Marshal serializes u's children, in URR ID, Other order.
+func (u *URR) Marshal() ([]byte, error) {
+	var ies []IE
+	if u.URRID != nil {
+		ies = append(ies, *u.URRID)
+	}
+	ies = append(ies, u.Other...)
+	return MarshalGrouped(ies...)
+}
+
+func parseURR(value []byte) (IE, error) {
+	children, err := UnmarshalGrouped(value)
+	if err != nil {
+		return nil, fmt.Errorf("pfcp: Create URR: %w", err)
+	}
+	u := &URR{}
+	for _, c := range children {
+		switch v := c.(type) {
+		case URRID:
+			if u.URRID == nil {
+				u.URRID = &v
+				continue
+			}
+		}
+		u.Other = append(u.Other, c)
+	}
+	return u, nil
+}
+
+func init() { RegisterIEType(IETypeCreateURR, parseURR) }
@@ -0,0 +1,145 @@
+package pfcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFAR_NestedRoundTrip(t *testing.T) {
+	ohc, err := NewGTPUv4(42, net.IP{192, 0, 2, 9})
+	require.NoError(t, err)
+
+	dstIface := DestinationInterface(InterfaceCore)
+	farID := FARID(5)
+	action := ApplyAction(ApplyActionForw)
+
+	far := &FAR{
+		FARID:       &farID,
+		ApplyAction: &action,
+		ForwardingParameters: &ForwardingParameters{
+			DestinationInterface: &dstIface,
+			OuterHeaderCreation:  ohc,
+		},
+	}
+
+	raw, err := far.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseFAR(raw)
+	require.NoError(t, err)
+	got, ok := ie.(*FAR)
+	require.True(t, ok)
+
+	require.NotNil(t, got.FARID)
+	assert.Equal(t, FARID(5), *got.FARID)
+	require.NotNil(t, got.ApplyAction)
+	assert.Equal(t, ApplyAction(ApplyActionForw), *got.ApplyAction)
+	require.NotNil(t, got.ForwardingParameters)
+	require.NotNil(t, got.ForwardingParameters.DestinationInterface)
+	assert.Equal(t, DestinationInterface(InterfaceCore), *got.ForwardingParameters.DestinationInterface)
+	require.NotNil(t, got.ForwardingParameters.OuterHeaderCreation)
+	assert.Equal(t, uint32(42), got.ForwardingParameters.OuterHeaderCreation.TEID)
+}
+
+func TestPDR_NestedRoundTrip(t *testing.T) {
+	srcIface := SourceInterface(InterfaceAccess)
+	pdrID := PDRID(1)
+	precedence := Precedence(100)
+	farID := FARID(1)
+
+	pdr := &PDR{
+		PDRID:      &pdrID,
+		Precedence: &precedence,
+		PDI: &PDI{
+			SourceInterface: &srcIface,
+			FTEID:           &FTEID{TEID: 9, IPv4Address: net.IP{10, 0, 0, 1}},
+		},
+		FARID: &farID,
+	}
+
+	raw, err := pdr.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parsePDR(raw)
+	require.NoError(t, err)
+	got := ie.(*PDR)
+
+	require.NotNil(t, got.PDI)
+	require.NotNil(t, got.PDI.SourceInterface)
+	assert.Equal(t, SourceInterface(InterfaceAccess), *got.PDI.SourceInterface)
+	require.NotNil(t, got.PDI.FTEID)
+	assert.Equal(t, uint32(9), got.PDI.FTEID.TEID)
+	require.NotNil(t, got.FARID)
+	assert.Equal(t, FARID(1), *got.FARID)
+}
+
+func TestQER_RoundTrip(t *testing.T) {
+	id := QERID(3)
+	q := &QER{QERID: &id}
+
+	raw, err := q.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseQER(raw)
+	require.NoError(t, err)
+	got := ie.(*QER)
+	require.NotNil(t, got.QERID)
+	assert.Equal(t, QERID(3), *got.QERID)
+}
+
+func TestURR_RoundTrip(t *testing.T) {
+	id := URRID(4)
+	u := &URR{URRID: &id}
+
+	raw, err := u.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseURR(raw)
+	require.NoError(t, err)
+	got := ie.(*URR)
+	require.NotNil(t, got.URRID)
+	assert.Equal(t, URRID(4), *got.URRID)
+}
+
+func TestForwardingParameters_PreservesUnknownChild(t *testing.T) {
+	fp := &ForwardingParameters{
+		Other: []IE{&UnknownIE{TypeCode: 0xFFFE, Value: []byte{1, 2}}},
+	}
+
+	raw, err := fp.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseForwardingParameters(raw)
+	require.NoError(t, err)
+	got := ie.(*ForwardingParameters)
+	require.Len(t, got.Other, 1)
+	assert.Equal(t, uint16(0xFFFE), got.Other[0].Type())
+}
+
+func TestParsePDI_PreservesDuplicateKnownChild(t *testing.T) {
+	first := SourceInterface(InterfaceAccess)
+	second := SourceInterface(InterfaceCore)
+	value, err := MarshalGrouped(first, second)
+	require.NoError(t, err)
+
+	ie, err := parsePDI(value)
+	require.NoError(t, err)
+	got := ie.(*PDI)
+
+	require.NotNil(t, got.SourceInterface)
+	assert.Equal(t, first, *got.SourceInterface)
+	require.Len(t, got.Other, 1)
+	assert.Equal(t, second, got.Other[0])
+}
+
+func TestFAR_ImplementsIE(t *testing.T) {
+	var _ IE = (*FAR)(nil)
+	var _ IE = (*PDR)(nil)
+	var _ IE = (*PDI)(nil)
+	var _ IE = (*ForwardingParameters)(nil)
+	var _ IE = (*QER)(nil)
+	var _ IE = (*URR)(nil)
+}
@@ -0,0 +1,136 @@
+package pfcp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// IE type values, per 3GPP TS 29.244 Table 8.1.2-1. Only the values needed
+// by the IEs implemented in this package are listed; the registry in this
+// file works with any type value, known or not.
+const (
+	IETypeCreatePDR            uint16 = 1
+	IETypePDI                  uint16 = 2
+	IETypeCreateFAR            uint16 = 3
+	IETypeForwardingParameters uint16 = 4
+	IETypeCreateURR            uint16 = 6
+	IETypeCreateQER            uint16 = 7
+	IETypeSourceInterface      uint16 = 20
+	IETypeFTEID                uint16 = 21
+	IETypePrecedence           uint16 = 29
+	IETypeDestinationInterface uint16 = 42
+	IETypeApplyAction          uint16 = 44
+	IETypePDRID                uint16 = 56
+	IETypeOuterHeaderCreation  uint16 = 84
+	IETypeURRID                uint16 = 81
+	IETypeFARID                uint16 = 108
+	IETypeQERID                uint16 = 109
+)
+
+// IE is a single PFCP Information Element: something that can report its own
+// IE type and serialize its value (the bytes that follow the Type/Length
+// header described in TS 29.244 §8.1.2).
+type IE interface {
+	Type() uint16
+	Marshal() ([]byte, error)
+}
+
+// unmarshalFunc parses an IE's value bytes (i.e. the bytes after the
+// Type/Length header) into an IE of the concrete type registered for it.
+type unmarshalFunc func(value []byte) (IE, error)
+
+var ieRegistry = map[uint16]unmarshalFunc{}
+
+// RegisterIEType registers the parser for an IE type, so that Unmarshal (and
+// UnmarshalGrouped) can produce the concrete IE type instead of UnknownIE.
+// Intended to be called from an init() alongside the IE's definition; see
+// outer_header.go for an example.
+func RegisterIEType(t uint16, fn func(value []byte) (IE, error)) {
+	ieRegistry[t] = fn
+}
+
+// UnknownIE is returned by Unmarshal for an IE type with no registered
+// parser. It preserves the type and raw value unchanged, so a message that
+// is decoded, inspected, and re-marshaled round-trips IEs it doesn't
+// understand instead of dropping them; this is the package's permissive
+// decoding mode.
+type UnknownIE struct {
+	TypeCode uint16
+	Value    []byte
+}
+
+// Type returns the IE's type code.
+func (u *UnknownIE) Type() uint16 { return u.TypeCode }
+
+// Marshal returns the original value bytes unchanged.
+func (u *UnknownIE) Marshal() ([]byte, error) {
+	return append([]byte(nil), u.Value...), nil
+}
+
+// Unmarshal decodes a single TLV-encoded IE: a 2-octet Type, a 2-octet
+// Length, and Length octets of value, per TS 29.244 §8.1.2. IE types with no
+// registered parser decode as *UnknownIE rather than failing, so that
+// callers can skip IEs they don't recognize while still being able to
+// re-marshal them unchanged.
+func Unmarshal(raw []byte) (IE, error) {
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("pfcp: IE header too short: need at least 4 bytes, got %d", len(raw))
+	}
+	t := binary.BigEndian.Uint16(raw[0:2])
+	l := binary.BigEndian.Uint16(raw[2:4])
+	if len(raw) < 4+int(l) {
+		return nil, fmt.Errorf("pfcp: IE type %d declares length %d but only %d bytes remain", t, l, len(raw)-4)
+	}
+	value := raw[4 : 4+int(l)]
+
+	fn, ok := ieRegistry[t]
+	if !ok {
+		return &UnknownIE{TypeCode: t, Value: append([]byte(nil), value...)}, nil
+	}
+	return fn(value)
+}
+
+// MarshalGrouped serializes a sequence of child IEs into a grouped IE's
+// value: each child is prefixed with its own Type/Length header, back to
+// back, per TS 29.244 §8.1.2.
+func MarshalGrouped(ies ...IE) ([]byte, error) {
+	var buf []byte
+	for _, ie := range ies {
+		v, err := ie.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("pfcp: marshaling IE type %d: %w", ie.Type(), err)
+		}
+		if len(v) > 0xffff {
+			return nil, fmt.Errorf("pfcp: IE type %d value of %d bytes exceeds the 16-bit Length field", ie.Type(), len(v))
+		}
+		hdr := make([]byte, 4)
+		binary.BigEndian.PutUint16(hdr[0:2], ie.Type())
+		binary.BigEndian.PutUint16(hdr[2:4], uint16(len(v)))
+		buf = append(buf, hdr...)
+		buf = append(buf, v...)
+	}
+	return buf, nil
+}
+
+// UnmarshalGrouped decodes a grouped IE's value into its child IEs, in wire
+// order. Like Unmarshal, unrecognized child IE types decode as *UnknownIE
+// instead of failing.
+func UnmarshalGrouped(value []byte) ([]IE, error) {
+	var ies []IE
+	for len(value) > 0 {
+		if len(value) < 4 {
+			return nil, fmt.Errorf("pfcp: grouped IE has %d trailing bytes, too short for an IE header", len(value))
+		}
+		l := binary.BigEndian.Uint16(value[2:4])
+		if len(value) < 4+int(l) {
+			return nil, fmt.Errorf("pfcp: grouped IE child declares length %d but only %d bytes remain", l, len(value)-4)
+		}
+		ie, err := Unmarshal(value[:4+int(l)])
+		if err != nil {
+			return nil, err
+		}
+		ies = append(ies, ie)
+		value = value[4+int(l):]
+	}
+	return ies, nil
+}
@@ -0,0 +1,99 @@
+package pfcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOuterHeaderCreationFields_ImplementsIE(t *testing.T) {
+	fields, err := NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	var ie IE = fields
+	assert.Equal(t, IETypeOuterHeaderCreation, ie.Type())
+
+	raw, err := ie.Marshal()
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+}
+
+func TestUnmarshal_KnownType(t *testing.T) {
+	fields, err := NewGTPUv4(0xAABBCCDD, net.IP{192, 0, 2, 1})
+	require.NoError(t, err)
+	value, err := fields.Marshal()
+	require.NoError(t, err)
+
+	raw := tlv(t, IETypeOuterHeaderCreation, value)
+
+	ie, err := Unmarshal(raw)
+	require.NoError(t, err)
+	ohc, ok := ie.(*OuterHeaderCreationFields)
+	require.True(t, ok)
+	assert.Equal(t, uint32(0xAABBCCDD), ohc.TEID)
+}
+
+func TestUnmarshal_UnknownTypePreservesBytes(t *testing.T) {
+	raw := tlv(t, 0xFFFE, []byte{1, 2, 3, 4})
+
+	ie, err := Unmarshal(raw)
+	require.NoError(t, err)
+	unknown, ok := ie.(*UnknownIE)
+	require.True(t, ok)
+	assert.Equal(t, uint16(0xFFFE), unknown.Type())
+	assert.Equal(t, []byte{1, 2, 3, 4}, unknown.Value)
+
+	back, err := unknown.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 4}, back)
+}
+
+func TestUnmarshal_RejectsShortHeader(t *testing.T) {
+	_, err := Unmarshal([]byte{0, 1, 0})
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_RejectsTruncatedValue(t *testing.T) {
+	_, err := Unmarshal([]byte{0, 1, 0, 10, 1, 2})
+	assert.Error(t, err)
+}
+
+func TestMarshalUnmarshalGrouped_RoundTrip(t *testing.T) {
+	ies := []IE{SourceInterface(InterfaceAccess), FARID(7)}
+
+	value, err := MarshalGrouped(ies...)
+	require.NoError(t, err)
+
+	children, err := UnmarshalGrouped(value)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.Equal(t, SourceInterface(InterfaceAccess), children[0])
+	assert.Equal(t, FARID(7), children[1])
+}
+
+func TestUnmarshalGrouped_PreservesUnknownChild(t *testing.T) {
+	value, err := MarshalGrouped(FARID(1))
+	require.NoError(t, err)
+	value = append(value, tlv(t, 0xFFFE, []byte{9, 9})...)
+
+	children, err := UnmarshalGrouped(value)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	unknown, ok := children[1].(*UnknownIE)
+	require.True(t, ok)
+	assert.Equal(t, uint16(0xFFFE), unknown.TypeCode)
+}
+
+func TestUnmarshalGrouped_RejectsTruncatedChild(t *testing.T) {
+	_, err := UnmarshalGrouped([]byte{0, 1, 0, 10, 1, 2})
+	assert.Error(t, err)
+}
+
+// tlv builds a Type/Length/Value-framed IE for tests.
+func tlv(t *testing.T, typ uint16, value []byte) []byte {
+	t.Helper()
+	buf := []byte{byte(typ >> 8), byte(typ), byte(len(value) >> 8), byte(len(value))}
+	return append(buf, value...)
+}
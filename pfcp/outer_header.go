@@ -60,7 +60,7 @@ func ParseOuterHeaderCreation(payload []byte) (*OuterHeaderCreationFields, error
 		if l < offset+4 {
 			return nil, fmt.Errorf("OuterHeaderCreation: insufficient bytes for IPv4 at offset %d", offset)
 		}
-		f.IPv4Address = net.IP(payload[offset : offset+4]).To4()
+		f.IPv4Address = append(net.IP(nil), payload[offset:offset+4]...)
 		offset += 4
 	}
 
@@ -69,7 +69,7 @@ func ParseOuterHeaderCreation(payload []byte) (*OuterHeaderCreationFields, error
 		if l < offset+16 {
 			return nil, fmt.Errorf("OuterHeaderCreation: insufficient bytes for IPv6 at offset %d", offset)
 		}
-		f.IPv6Address = net.IP(payload[offset : offset+16]).To16()
+		f.IPv6Address = append(net.IP(nil), payload[offset:offset+16]...)
 		offset += 16
 	}
 
@@ -114,3 +114,193 @@ func (f *OuterHeaderCreationFields) HasIPv4() bool {
 	oct5 := uint8((f.OuterHeaderCreationDescription & 0xff00) >> 8)
 	return (oct5&0x01) != 0 || (oct5&0x04) != 0 || (oct5&0x10) != 0
 }
+
+// HasIPv6 checks if OuterHeaderCreation has IPv6 field
+func (f *OuterHeaderCreationFields) HasIPv6() bool {
+	oct5 := f.oct5()
+	return (oct5&0x02) != 0 || (oct5&0x08) != 0 || (oct5&0x20) != 0
+}
+
+// HasPort checks if OuterHeaderCreation has a Port Number field
+func (f *OuterHeaderCreationFields) HasPort() bool {
+	oct5 := f.oct5()
+	return (oct5&0x04) != 0 || (oct5&0x08) != 0
+}
+
+// HasCTag checks if OuterHeaderCreation has a C-TAG field
+func (f *OuterHeaderCreationFields) HasCTag() bool {
+	return (f.oct5() & 0x40) != 0
+}
+
+// HasSTag checks if OuterHeaderCreation has an S-TAG field
+func (f *OuterHeaderCreationFields) HasSTag() bool {
+	return (f.oct5() & 0x80) != 0
+}
+
+func (f *OuterHeaderCreationFields) oct5() uint8 {
+	return uint8((f.OuterHeaderCreationDescription & 0xff00) >> 8)
+}
+
+// Type returns IETypeOuterHeaderCreation, implementing the IE interface.
+func (f *OuterHeaderCreationFields) Type() uint16 { return IETypeOuterHeaderCreation }
+
+func init() {
+	RegisterIEType(IETypeOuterHeaderCreation, func(value []byte) (IE, error) {
+		return ParseOuterHeaderCreation(value)
+	})
+}
+
+// Outer Header Creation Description flag bits (Octet 5), per TS 29.244 Figure 8.2.56-1.
+const (
+	ohcFlagGTPUv4 = 0x01
+	ohcFlagGTPUv6 = 0x02
+	ohcFlagUDPv4  = 0x04
+	ohcFlagUDPv6  = 0x08
+	ohcFlagIPv4   = 0x10
+	ohcFlagIPv6   = 0x20
+	ohcFlagCTag   = 0x40
+	ohcFlagSTag   = 0x80
+)
+
+// maxTagValue is the largest value a 3-octet C-TAG/S-TAG field can hold.
+const maxTagValue = 0xFFFFFF
+
+// Marshal serializes f into the Outer Header Creation IE wire format described
+// in TS 29.244 §8.2.56, the inverse of ParseOuterHeaderCreation. It returns an
+// error if the fields set are inconsistent with the Octet 5 flags (e.g. an
+// IPv4 flag is set but IPv4Address is not a valid 4-byte address), or if a
+// C-TAG/S-TAG value does not fit in 3 octets.
+func (f *OuterHeaderCreationFields) Marshal() ([]byte, error) {
+	oct5 := f.oct5()
+	oct6 := uint8(f.OuterHeaderCreationDescription & 0xff)
+	buf := make([]byte, 2, 2+4+16+2+3+3)
+	buf[0], buf[1] = oct5, oct6
+
+	if f.HasTEID() {
+		buf = append(buf, byte(f.TEID>>24), byte(f.TEID>>16), byte(f.TEID>>8), byte(f.TEID))
+	}
+
+	if f.HasIPv4() {
+		ip4 := f.IPv4Address.To4()
+		if ip4 == nil {
+			return nil, fmt.Errorf("OuterHeaderCreation: flags require IPv4 but IPv4Address %v is not a valid IPv4 address", f.IPv4Address)
+		}
+		buf = append(buf, ip4...)
+	}
+
+	if f.HasIPv6() {
+		ip6 := f.IPv6Address.To16()
+		if ip6 == nil || f.IPv6Address.To4() != nil {
+			return nil, fmt.Errorf("OuterHeaderCreation: flags require IPv6 but IPv6Address %v is not a valid IPv6 address", f.IPv6Address)
+		}
+		buf = append(buf, ip6...)
+	}
+
+	if f.HasPort() {
+		buf = append(buf, byte(f.PortNumber>>8), byte(f.PortNumber))
+	}
+
+	if f.HasCTag() {
+		if f.CTag > maxTagValue {
+			return nil, fmt.Errorf("OuterHeaderCreation: C-TAG %#x exceeds 3-octet range", f.CTag)
+		}
+		buf = append(buf, byte(f.CTag>>16), byte(f.CTag>>8), byte(f.CTag))
+	}
+
+	if f.HasSTag() {
+		if f.STag > maxTagValue {
+			return nil, fmt.Errorf("OuterHeaderCreation: S-TAG %#x exceeds 3-octet range", f.STag)
+		}
+		buf = append(buf, byte(f.STag>>16), byte(f.STag>>8), byte(f.STag))
+	}
+
+	return buf, nil
+}
+
+// BuildOuterHeaderCreation constructs an OuterHeaderCreationFields from its
+// raw Octet 5 flag byte and constituent field values, validating that the
+// supplied values are consistent with those flags. Unused fields for a given
+// flag combination are ignored (e.g. ipv6/port are ignored for the GTP-U/IPv4
+// flag). Most callers should prefer the typed constructors below (NewGTPUv4,
+// NewGTPUv6, NewUDPv4, NewUDPv6, NewIPv4, NewIPv6).
+func BuildOuterHeaderCreation(oct5 uint8, teid uint32, ipv4, ipv6 net.IP, port uint16) (*OuterHeaderCreationFields, error) {
+	f := &OuterHeaderCreationFields{
+		OuterHeaderCreationDescription: uint16(oct5) << 8,
+	}
+
+	if f.HasTEID() {
+		f.TEID = teid
+	}
+	if f.HasIPv4() {
+		f.IPv4Address = ipv4
+	}
+	if f.HasIPv6() {
+		f.IPv6Address = ipv6
+	}
+	if f.HasPort() {
+		f.PortNumber = port
+	}
+
+	if _, err := f.Marshal(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewGTPUv4 builds Outer Header Creation fields for the GTP-U/UDP/IPv4 case
+// (Octet 5 bit 1).
+func NewGTPUv4(teid uint32, ip net.IP) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagGTPUv4, teid, ip, nil, 0)
+}
+
+// NewGTPUv6 builds Outer Header Creation fields for the GTP-U/UDP/IPv6 case
+// (Octet 5 bit 2).
+func NewGTPUv6(teid uint32, ip net.IP) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagGTPUv6, teid, nil, ip, 0)
+}
+
+// NewUDPv4 builds Outer Header Creation fields for the plain UDP/IPv4 case
+// (Octet 5 bit 3).
+func NewUDPv4(ip net.IP, port uint16) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagUDPv4, 0, ip, nil, port)
+}
+
+// NewUDPv6 builds Outer Header Creation fields for the plain UDP/IPv6 case
+// (Octet 5 bit 4).
+func NewUDPv6(ip net.IP, port uint16) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagUDPv6, 0, nil, ip, port)
+}
+
+// NewIPv4 builds Outer Header Creation fields for the IPv4-only case (Octet 5
+// bit 5).
+func NewIPv4(ip net.IP) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagIPv4, 0, ip, nil, 0)
+}
+
+// NewIPv6 builds Outer Header Creation fields for the IPv6-only case (Octet 5
+// bit 6).
+func NewIPv6(ip net.IP) (*OuterHeaderCreationFields, error) {
+	return BuildOuterHeaderCreation(ohcFlagIPv6, 0, nil, ip, 0)
+}
+
+// WithCTag sets the C-TAG flag (Octet 5 bit 7) and value, returning an error
+// if ctag does not fit in 3 octets.
+func (f *OuterHeaderCreationFields) WithCTag(ctag uint32) (*OuterHeaderCreationFields, error) {
+	if ctag > maxTagValue {
+		return nil, fmt.Errorf("OuterHeaderCreation: C-TAG %#x exceeds 3-octet range", ctag)
+	}
+	f.OuterHeaderCreationDescription |= ohcFlagCTag << 8
+	f.CTag = ctag
+	return f, nil
+}
+
+// WithSTag sets the S-TAG flag (Octet 5 bit 8) and value, returning an error
+// if stag does not fit in 3 octets.
+func (f *OuterHeaderCreationFields) WithSTag(stag uint32) (*OuterHeaderCreationFields, error) {
+	if stag > maxTagValue {
+		return nil, fmt.Errorf("OuterHeaderCreation: S-TAG %#x exceeds 3-octet range", stag)
+	}
+	f.OuterHeaderCreationDescription |= ohcFlagSTag << 8
+	f.STag = stag
+	return f, nil
+}
@@ -272,6 +272,147 @@ func TestHasTEID(t *testing.T) {
 	}
 }
 
+func TestMarshal_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{"GTPUv4", []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01}},
+		{"GTPUv6", []byte{
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x02,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		}},
+		{"UDPv4", []byte{0x04, 0x00, 0xc0, 0xa8, 0x01, 0x01, 0x1f, 0x90}},
+		{"UDPv6", []byte{
+			0x08, 0x00,
+			0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			0x00, 0x50,
+		}},
+		{"IPv4Only", []byte{0x10, 0x00, 0xac, 0x10, 0x00, 0x01}},
+		{"IPv6Only", []byte{
+			0x20, 0x00,
+			0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		}},
+		{"CTag", []byte{0x41, 0x00, 0x00, 0x00, 0x00, 0x05, 0x0a, 0x00, 0x00, 0x01, 0x12, 0x34, 0x56}},
+		{"STag", []byte{0x81, 0x00, 0x00, 0x00, 0x00, 0x0a, 0xc0, 0xa8, 0x00, 0x01, 0xab, 0xcd, 0xef}},
+		{"CTagAndSTag", []byte{
+			0xc1, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x0a, 0x01, 0x02, 0x03,
+			0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseOuterHeaderCreation(tt.payload)
+			require.NoError(t, err)
+
+			out, err := f.Marshal()
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload, out)
+
+			roundTripped, err := ParseOuterHeaderCreation(out)
+			require.NoError(t, err)
+			assert.Equal(t, f, roundTripped)
+		})
+	}
+}
+
+func TestNewGTPUv4_Marshal(t *testing.T) {
+	f, err := NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	out, err := f.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01}, out)
+}
+
+func TestNewGTPUv6_Marshal(t *testing.T) {
+	f, err := NewGTPUv6(2, net.ParseIP("::1"))
+	require.NoError(t, err)
+
+	out, err := f.Marshal()
+	require.NoError(t, err)
+
+	roundTripped, err := ParseOuterHeaderCreation(out)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), roundTripped.TEID)
+	assert.Equal(t, net.ParseIP("::1"), roundTripped.IPv6Address)
+}
+
+func TestNewUDPv4_Marshal(t *testing.T) {
+	f, err := NewUDPv4(net.IP{192, 168, 1, 1}, 8080)
+	require.NoError(t, err)
+
+	out, err := f.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x04, 0x00, 0xc0, 0xa8, 0x01, 0x01, 0x1f, 0x90}, out)
+}
+
+func TestWithCTagAndSTag(t *testing.T) {
+	f, err := NewGTPUv4(15, net.IP{10, 1, 2, 3})
+	require.NoError(t, err)
+
+	_, err = f.WithCTag(0xaabbcc)
+	require.NoError(t, err)
+	_, err = f.WithSTag(0xddeeff)
+	require.NoError(t, err)
+
+	out, err := f.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0xc1, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x0a, 0x01, 0x02, 0x03,
+		0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+	}, out)
+}
+
+func TestMarshal_RejectsNonIPv4ForIPv4Flag(t *testing.T) {
+	f := &OuterHeaderCreationFields{
+		OuterHeaderCreationDescription: 0x0100,
+		TEID:                           1,
+		IPv4Address:                    net.ParseIP("::1"),
+	}
+	_, err := f.Marshal()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "IPv4")
+}
+
+func TestMarshal_RejectsNonIPv6ForIPv6Flag(t *testing.T) {
+	f := &OuterHeaderCreationFields{
+		OuterHeaderCreationDescription: 0x0200,
+		TEID:                           1,
+		IPv6Address:                    net.IP{10, 0, 0, 1},
+	}
+	_, err := f.Marshal()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "IPv6")
+}
+
+func TestNewGTPUv4_RejectsNonIPv4(t *testing.T) {
+	_, err := NewGTPUv4(1, net.ParseIP("::1"))
+	assert.Error(t, err)
+}
+
+func TestWithCTag_RejectsOutOfRange(t *testing.T) {
+	f, err := NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	_, err = f.WithCTag(0x1000000)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "C-TAG")
+}
+
+func TestWithSTag_RejectsOutOfRange(t *testing.T) {
+	f, err := NewGTPUv4(1, net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+
+	_, err = f.WithSTag(0x1000000)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "S-TAG")
+}
+
 func TestHasIPv4(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,297 @@
+package pfcp
+
+import (
+	"fmt"
+	"net"
+)
+
+// Interface values shared by the Source Interface and Destination Interface
+// IEs, per TS 29.244 Table 8.2.2-1.
+const (
+	InterfaceAccess       = 0
+	InterfaceCore         = 1
+	InterfaceSGiLANN6LAN  = 2
+	InterfaceCPFunction   = 3
+	Interface5GVNInternal = 4
+)
+
+// SourceInterface is the Source Interface IE (TS 29.244 §8.2.2): a single
+// octet carrying one of the Interface* values.
+type SourceInterface uint8
+
+// Type returns IETypeSourceInterface.
+func (s SourceInterface) Type() uint16 { return IETypeSourceInterface }
+
+// Marshal returns the single-octet wire encoding.
+func (s SourceInterface) Marshal() ([]byte, error) { return []byte{byte(s)}, nil }
+
+func parseSourceInterface(value []byte) (IE, error) {
+	if len(value) < 1 {
+		return nil, fmt.Errorf("pfcp: Source Interface payload too short: need at least 1 byte")
+	}
+	return SourceInterface(value[0]), nil
+}
+
+func init() { RegisterIEType(IETypeSourceInterface, parseSourceInterface) }
+
+// DestinationInterface is the Destination Interface IE (TS 29.244 §8.2.24):
+// a single octet carrying one of the Interface* values.
+type DestinationInterface uint8
+
+// Type returns IETypeDestinationInterface.
+func (d DestinationInterface) Type() uint16 { return IETypeDestinationInterface }
+
+// Marshal returns the single-octet wire encoding.
+func (d DestinationInterface) Marshal() ([]byte, error) { return []byte{byte(d)}, nil }
+
+func parseDestinationInterface(value []byte) (IE, error) {
+	if len(value) < 1 {
+		return nil, fmt.Errorf("pfcp: Destination Interface payload too short: need at least 1 byte")
+	}
+	return DestinationInterface(value[0]), nil
+}
+
+func init() { RegisterIEType(IETypeDestinationInterface, parseDestinationInterface) }
+
+// PDRID is the PDR ID IE (TS 29.244 §8.2.34): a 2-octet rule identifier.
+type PDRID uint16
+
+// Type returns IETypePDRID.
+func (id PDRID) Type() uint16 { return IETypePDRID }
+
+// Marshal returns the 2-octet big-endian wire encoding.
+func (id PDRID) Marshal() ([]byte, error) { return []byte{byte(id >> 8), byte(id)}, nil }
+
+func parsePDRID(value []byte) (IE, error) {
+	if len(value) < 2 {
+		return nil, fmt.Errorf("pfcp: PDR ID payload too short: need at least 2 bytes")
+	}
+	return PDRID(uint16(value[0])<<8 | uint16(value[1])), nil
+}
+
+func init() { RegisterIEType(IETypePDRID, parsePDRID) }
+
+// FARID is the FAR ID IE (TS 29.244 §8.2.36): a 4-octet rule identifier.
+type FARID uint32
+
+// Type returns IETypeFARID.
+func (id FARID) Type() uint16 { return IETypeFARID }
+
+// Marshal returns the 4-octet big-endian wire encoding.
+func (id FARID) Marshal() ([]byte, error) {
+	return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}, nil
+}
+
+func parseFARID(value []byte) (IE, error) {
+	if len(value) < 4 {
+		return nil, fmt.Errorf("pfcp: FAR ID payload too short: need at least 4 bytes")
+	}
+	return FARID(uint32(value[0])<<24 | uint32(value[1])<<16 | uint32(value[2])<<8 | uint32(value[3])), nil
+}
+
+func init() { RegisterIEType(IETypeFARID, parseFARID) }
+
+// QERID is the QER ID IE (TS 29.244 §8.2.75): a 4-octet rule identifier.
+type QERID uint32
+
+// Type returns IETypeQERID.
+func (id QERID) Type() uint16 { return IETypeQERID }
+
+// Marshal returns the 4-octet big-endian wire encoding.
+func (id QERID) Marshal() ([]byte, error) {
+	return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}, nil
+}
+
+func parseQERID(value []byte) (IE, error) {
+	if len(value) < 4 {
+		return nil, fmt.Errorf("pfcp: QER ID payload too short: need at least 4 bytes")
+	}
+	return QERID(uint32(value[0])<<24 | uint32(value[1])<<16 | uint32(value[2])<<8 | uint32(value[3])), nil
+}
+
+func init() { RegisterIEType(IETypeQERID, parseQERID) }
+
+// URRID is the URR ID IE (TS 29.244 §8.2.54): a 4-octet rule identifier.
+type URRID uint32
+
+// Type returns IETypeURRID.
+func (id URRID) Type() uint16 { return IETypeURRID }
+
+// Marshal returns the 4-octet big-endian wire encoding.
+func (id URRID) Marshal() ([]byte, error) {
+	return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}, nil
+}
+
+func parseURRID(value []byte) (IE, error) {
+	if len(value) < 4 {
+		return nil, fmt.Errorf("pfcp: URR ID payload too short: need at least 4 bytes")
+	}
+	return URRID(uint32(value[0])<<24 | uint32(value[1])<<16 | uint32(value[2])<<8 | uint32(value[3])), nil
+}
+
+func init() { RegisterIEType(IETypeURRID, parseURRID) }
+
+// Precedence is the Precedence IE (TS 29.244 §8.2.10): a 4-octet value used
+// to order a session's PDRs, lower values matching first.
+type Precedence uint32
+
+// Type returns IETypePrecedence.
+func (p Precedence) Type() uint16 { return IETypePrecedence }
+
+// Marshal returns the 4-octet big-endian wire encoding.
+func (p Precedence) Marshal() ([]byte, error) {
+	return []byte{byte(p >> 24), byte(p >> 16), byte(p >> 8), byte(p)}, nil
+}
+
+func parsePrecedence(value []byte) (IE, error) {
+	if len(value) < 4 {
+		return nil, fmt.Errorf("pfcp: Precedence payload too short: need at least 4 bytes")
+	}
+	return Precedence(uint32(value[0])<<24 | uint32(value[1])<<16 | uint32(value[2])<<8 | uint32(value[3])), nil
+}
+
+func init() { RegisterIEType(IETypePrecedence, parsePrecedence) }
+
+// Apply Action flag bits (Octet 5), per TS 29.244 Table 8.2.26-1. Only the
+// Rel-15 baseline actions are modeled; the IE is a single octet here, not
+// the variable-length encoding later releases added.
+const (
+	ApplyActionDrop = 0x01
+	ApplyActionForw = 0x02
+	ApplyActionBuff = 0x04
+	ApplyActionNoCP = 0x08
+	ApplyActionDupl = 0x10
+)
+
+// ApplyAction is the Apply Action IE (TS 29.244 §8.2.26): a bitmask of
+// ApplyAction* flags describing what a FAR does with matched packets.
+type ApplyAction uint8
+
+// Type returns IETypeApplyAction.
+func (a ApplyAction) Type() uint16 { return IETypeApplyAction }
+
+// Marshal returns the single-octet wire encoding.
+func (a ApplyAction) Marshal() ([]byte, error) { return []byte{byte(a)}, nil }
+
+func parseApplyAction(value []byte) (IE, error) {
+	if len(value) < 1 {
+		return nil, fmt.Errorf("pfcp: Apply Action payload too short: need at least 1 byte")
+	}
+	return ApplyAction(value[0]), nil
+}
+
+func init() { RegisterIEType(IETypeApplyAction, parseApplyAction) }
+
+// F-TEID flag bits (Octet 5), per TS 29.244 Figure 8.2.3-1.
+const (
+	fteidFlagV4   = 0x01
+	fteidFlagV6   = 0x02
+	fteidFlagCh   = 0x04
+	fteidFlagChID = 0x08
+)
+
+// FTEID is the F-TEID IE (TS 29.244 §8.2.3): a Fully Qualified TEID,
+// identifying a GTP-U tunnel endpoint by TEID plus IPv4 and/or IPv6 address,
+// or requesting the UP function choose one (Choose/ChooseID).
+type FTEID struct {
+	TEID        uint32
+	IPv4Address net.IP
+	IPv6Address net.IP
+	Choose      bool
+	ChooseID    bool
+	ChooseIDVal uint8
+}
+
+// Type returns IETypeFTEID.
+func (f *FTEID) Type() uint16 { return IETypeFTEID }
+
+// Marshal serializes f into the F-TEID wire format. When Choose is set, TEID
+// and the addresses are omitted per TS 29.244 §8.2.3; otherwise at least one
+// of IPv4Address/IPv6Address must be set.
+func (f *FTEID) Marshal() ([]byte, error) {
+	var oct5 uint8
+	if f.Choose {
+		oct5 |= fteidFlagCh
+		if f.ChooseID {
+			oct5 |= fteidFlagChID
+		}
+		buf := []byte{oct5}
+		if f.ChooseID {
+			buf = append(buf, f.ChooseIDVal)
+		}
+		return buf, nil
+	}
+
+	hasV4 := f.IPv4Address.To4() != nil
+	hasV6 := f.IPv6Address != nil && f.IPv6Address.To4() == nil
+	if !hasV4 && !hasV6 {
+		return nil, fmt.Errorf("pfcp: F-TEID requires at least one of IPv4Address/IPv6Address when Choose is not set")
+	}
+	if hasV4 {
+		oct5 |= fteidFlagV4
+	}
+	if hasV6 {
+		oct5 |= fteidFlagV6
+	}
+
+	buf := make([]byte, 0, 1+4+4+16)
+	buf = append(buf, oct5)
+	buf = append(buf, byte(f.TEID>>24), byte(f.TEID>>16), byte(f.TEID>>8), byte(f.TEID))
+	if hasV4 {
+		buf = append(buf, f.IPv4Address.To4()...)
+	}
+	if hasV6 {
+		buf = append(buf, f.IPv6Address.To16()...)
+	}
+	return buf, nil
+}
+
+func parseFTEID(value []byte) (IE, error) {
+	if len(value) < 1 {
+		return nil, fmt.Errorf("pfcp: F-TEID payload too short: need at least 1 byte")
+	}
+	oct5 := value[0]
+	f := &FTEID{}
+	offset := 1
+
+	if oct5&fteidFlagCh != 0 {
+		f.Choose = true
+		if oct5&fteidFlagChID != 0 {
+			f.ChooseID = true
+			if len(value) < offset+1 {
+				return nil, fmt.Errorf("pfcp: F-TEID: insufficient bytes for Choose ID at offset %d", offset)
+			}
+			f.ChooseIDVal = value[offset]
+		}
+		return f, nil
+	}
+
+	if oct5&(fteidFlagV4|fteidFlagV6) == 0 {
+		return nil, fmt.Errorf("pfcp: F-TEID: neither Choose, V4, nor V6 flag is set in octet 5 %#x", oct5)
+	}
+
+	if len(value) < offset+4 {
+		return nil, fmt.Errorf("pfcp: F-TEID: insufficient bytes for TEID at offset %d", offset)
+	}
+	f.TEID = uint32(value[offset])<<24 | uint32(value[offset+1])<<16 | uint32(value[offset+2])<<8 | uint32(value[offset+3])
+	offset += 4
+
+	if oct5&fteidFlagV4 != 0 {
+		if len(value) < offset+4 {
+			return nil, fmt.Errorf("pfcp: F-TEID: insufficient bytes for IPv4 at offset %d", offset)
+		}
+		f.IPv4Address = net.IP(value[offset : offset+4]).To4()
+		offset += 4
+	}
+	if oct5&fteidFlagV6 != 0 {
+		if len(value) < offset+16 {
+			return nil, fmt.Errorf("pfcp: F-TEID: insufficient bytes for IPv6 at offset %d", offset)
+		}
+		f.IPv6Address = net.IP(value[offset : offset+16]).To16()
+		offset += 16
+	}
+
+	return f, nil
+}
+
+func init() { RegisterIEType(IETypeFTEID, parseFTEID) }
@@ -0,0 +1,127 @@
+package pfcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceInterface_Marshal(t *testing.T) {
+	raw, err := SourceInterface(InterfaceCore).Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{InterfaceCore}, raw)
+
+	ie, err := parseSourceInterface(raw)
+	require.NoError(t, err)
+	assert.Equal(t, SourceInterface(InterfaceCore), ie)
+}
+
+func TestDestinationInterface_Marshal(t *testing.T) {
+	raw, err := DestinationInterface(InterfaceAccess).Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseDestinationInterface(raw)
+	require.NoError(t, err)
+	assert.Equal(t, DestinationInterface(InterfaceAccess), ie)
+}
+
+func TestPDRID_RoundTrip(t *testing.T) {
+	raw, err := PDRID(0xABCD).Marshal()
+	require.NoError(t, err)
+
+	ie, err := parsePDRID(raw)
+	require.NoError(t, err)
+	assert.Equal(t, PDRID(0xABCD), ie)
+}
+
+func TestFARID_RoundTrip(t *testing.T) {
+	raw, err := FARID(0x11223344).Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseFARID(raw)
+	require.NoError(t, err)
+	assert.Equal(t, FARID(0x11223344), ie)
+}
+
+func TestPrecedence_RoundTrip(t *testing.T) {
+	raw, err := Precedence(100).Marshal()
+	require.NoError(t, err)
+
+	ie, err := parsePrecedence(raw)
+	require.NoError(t, err)
+	assert.Equal(t, Precedence(100), ie)
+}
+
+func TestApplyAction_RoundTrip(t *testing.T) {
+	raw, err := ApplyAction(ApplyActionForw | ApplyActionDupl).Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseApplyAction(raw)
+	require.NoError(t, err)
+	assert.Equal(t, ApplyAction(ApplyActionForw|ApplyActionDupl), ie)
+}
+
+func TestFTEID_RoundTrip_IPv4(t *testing.T) {
+	f := &FTEID{TEID: 0x12345678, IPv4Address: net.IP{192, 0, 2, 5}}
+
+	raw, err := f.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseFTEID(raw)
+	require.NoError(t, err)
+	got, ok := ie.(*FTEID)
+	require.True(t, ok)
+	assert.Equal(t, f.TEID, got.TEID)
+	assert.True(t, f.IPv4Address.Equal(got.IPv4Address))
+	assert.Nil(t, got.IPv6Address)
+}
+
+func TestFTEID_RoundTrip_DualStack(t *testing.T) {
+	f := &FTEID{
+		TEID:        7,
+		IPv4Address: net.IP{10, 0, 0, 1},
+		IPv6Address: net.ParseIP("2001:db8::1"),
+	}
+
+	raw, err := f.Marshal()
+	require.NoError(t, err)
+
+	ie, err := parseFTEID(raw)
+	require.NoError(t, err)
+	got := ie.(*FTEID)
+	assert.True(t, f.IPv4Address.Equal(got.IPv4Address))
+	assert.True(t, f.IPv6Address.Equal(got.IPv6Address))
+}
+
+func TestFTEID_RoundTrip_Choose(t *testing.T) {
+	f := &FTEID{Choose: true, ChooseID: true, ChooseIDVal: 3}
+
+	raw, err := f.Marshal()
+	require.NoError(t, err)
+	assert.Len(t, raw, 2)
+
+	ie, err := parseFTEID(raw)
+	require.NoError(t, err)
+	got := ie.(*FTEID)
+	assert.True(t, got.Choose)
+	assert.True(t, got.ChooseID)
+	assert.Equal(t, uint8(3), got.ChooseIDVal)
+}
+
+func TestFTEID_Marshal_RejectsNoAddressWithoutChoose(t *testing.T) {
+	f := &FTEID{TEID: 1}
+	_, err := f.Marshal()
+	assert.Error(t, err)
+}
+
+func TestFTEID_Parse_RejectsShortPayload(t *testing.T) {
+	_, err := parseFTEID(nil)
+	assert.Error(t, err)
+}
+
+func TestFTEID_Parse_RejectsNoAddressFlagsWithoutChoose(t *testing.T) {
+	_, err := parseFTEID([]byte{0x00, 0, 0, 0, 1})
+	assert.Error(t, err)
+}
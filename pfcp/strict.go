@@ -0,0 +1,104 @@
+package pfcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Presence describes whether a message table entry is required.
+type Presence int
+
+const (
+	// Optional IEs may be absent with no effect on validation.
+	Optional Presence = iota
+	// Mandatory IEs must be present.
+	Mandatory
+	// Conditional IEs are required only in circumstances this package does
+	// not attempt to model; ValidateStrict treats them like Optional.
+	Conditional
+)
+
+// IESpec is one row of a PFCP message's IE table (TS 29.244 clause 7.5.x):
+// an IE type, its presence requirement, and a human-readable name for error
+// messages.
+type IESpec struct {
+	Type     uint16
+	Presence Presence
+	Name     string
+}
+
+// MessageSchema is a message's IE table: the set of IEs it may carry and
+// which of them are mandatory.
+type MessageSchema struct {
+	Name string
+	IEs  []IESpec
+}
+
+// ValidateStrict checks that every Mandatory entry in schema has a
+// corresponding IE in ies, returning an error naming every missing one.
+// Conditional and Optional entries are not checked: this package does not
+// model the per-procedure conditions TS 29.244 attaches to them.
+func ValidateStrict(ies []IE, schema MessageSchema) error {
+	present := make(map[uint16]bool, len(ies))
+	for _, ie := range ies {
+		present[ie.Type()] = true
+	}
+
+	var missing []string
+	for _, spec := range schema.IEs {
+		if spec.Presence == Mandatory && !present[spec.Type] {
+			missing = append(missing, spec.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("pfcp: message %q missing mandatory IEs: %s", schema.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Message schemas for the grouped IEs this package implements, per TS 29.244
+// clause 7.5.2. These cover the IEs within reach of Outer Header Creation,
+// not an entire PFCP message (e.g. Session Establishment Request); callers
+// composing full messages should build their own MessageSchema the same
+// way.
+var (
+	// CreatePDRSchema is the Create PDR IE's table (TS 29.244 Table 7.5.2.2-1).
+	CreatePDRSchema = MessageSchema{
+		Name: "Create PDR",
+		IEs: []IESpec{
+			{Type: IETypePDRID, Presence: Mandatory, Name: "PDR ID"},
+			{Type: IETypePrecedence, Presence: Mandatory, Name: "Precedence"},
+			{Type: IETypePDI, Presence: Mandatory, Name: "PDI"},
+			{Type: IETypeFARID, Presence: Conditional, Name: "FAR ID"},
+		},
+	}
+
+	// CreateFARSchema is the Create FAR IE's table (TS 29.244 Table 7.5.2.3-1).
+	CreateFARSchema = MessageSchema{
+		Name: "Create FAR",
+		IEs: []IESpec{
+			{Type: IETypeFARID, Presence: Mandatory, Name: "FAR ID"},
+			{Type: IETypeApplyAction, Presence: Mandatory, Name: "Apply Action"},
+			{Type: IETypeForwardingParameters, Presence: Conditional, Name: "Forwarding Parameters"},
+		},
+	}
+
+	// PDISchema is the PDI IE's table (TS 29.244 Table 7.5.2.2-2).
+	PDISchema = MessageSchema{
+		Name: "PDI",
+		IEs: []IESpec{
+			{Type: IETypeSourceInterface, Presence: Mandatory, Name: "Source Interface"},
+			{Type: IETypeFTEID, Presence: Conditional, Name: "F-TEID"},
+		},
+	}
+
+	// ForwardingParametersSchema is the Forwarding Parameters IE's table
+	// (TS 29.244 Table 7.5.2.3-2).
+	ForwardingParametersSchema = MessageSchema{
+		Name: "Forwarding Parameters",
+		IEs: []IESpec{
+			{Type: IETypeDestinationInterface, Presence: Mandatory, Name: "Destination Interface"},
+			{Type: IETypeOuterHeaderCreation, Presence: Conditional, Name: "Outer Header Creation"},
+		},
+	}
+)
@@ -0,0 +1,37 @@
+package pfcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStrict_MissingMandatoryIE(t *testing.T) {
+	pdrID := PDRID(1)
+	ies := []IE{pdrID}
+
+	err := ValidateStrict(ies, CreatePDRSchema)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Precedence")
+	assert.Contains(t, err.Error(), "PDI")
+}
+
+func TestValidateStrict_AllMandatoryPresent(t *testing.T) {
+	ies := []IE{
+		PDRID(1),
+		Precedence(100),
+		&PDI{SourceInterface: func() *SourceInterface { s := SourceInterface(InterfaceAccess); return &s }()},
+	}
+
+	err := ValidateStrict(ies, CreatePDRSchema)
+	assert.NoError(t, err)
+}
+
+func TestValidateStrict_ConditionalAbsentIsNotAnError(t *testing.T) {
+	farID := FARID(1)
+	action := ApplyAction(ApplyActionForw)
+	ies := []IE{farID, action}
+
+	err := ValidateStrict(ies, CreateFARSchema)
+	assert.NoError(t, err)
+}